@@ -0,0 +1,192 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// NormalizeAggregateValue coerces a raw aggregation result, whose Go type
+// depends on the driver, to a well-defined type for the aggregation: count is
+// an int64, avg a float64, sum an int64 for integer results and a float64 for
+// real ones (as SQLite's SUM distinguishes them), and min/max keep the input's
+// type. Integer results never go through float64, so they keep their full
+// precision. A non-integral count means the driver returned something other
+// than a count and is an error rather than being truncated. NULL results
+// (e.g. the sum of no rows) stay nil. Numeric text, as some drivers return for
+// computed columns, is parsed as an integer when it is one and as a real
+// otherwise.
+func NormalizeAggregateValue(aggType AggregationType, value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if aggType != AggregationTypeCount && aggType != AggregationTypeSum && aggType != AggregationTypeAvg {
+		return value, nil
+	}
+
+	n, err := aggregateNumber(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s aggregation: %w", aggType, err)
+	}
+	i, isInt := n.(int64)
+	switch aggType {
+	case AggregationTypeCount:
+		if isInt {
+			return i, nil
+		}
+		f := n.(float64)
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return nil, fmt.Errorf("count aggregation: non-integral count %v", value)
+		}
+		return int64(f), nil
+	case AggregationTypeAvg:
+		if isInt {
+			return float64(i), nil
+		}
+	}
+	return n, nil
+}
+
+// aggregateNumber converts a raw aggregate value to an int64 or a float64.
+func aggregateNumber(value any) (any, error) {
+	if isInteger(value) {
+		if i, ok := toInt64(value); ok {
+			return i, nil
+		}
+	}
+	if n, ok := toFloat(value); ok {
+		return n, nil
+	}
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return nil, fmt.Errorf("unexpected value type %T", value)
+	}
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return i, nil
+	}
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, fmt.Errorf("non-numeric value %q", text)
+	}
+	return n, nil
+}
+
+// DefaultAggregationAlias returns the name an aggregation's result surfaces
+// under: its Alias when set, and otherwise the type and field joined with an
+// underscore, e.g. "count_user_id". Distinct aggregations get a ":distinct"
+// suffix, e.g. "count_user_id:distinct"; the colon cannot appear in an
+// unquoted identifier, so the name never collides with a plain aggregation of
+// a field such as "user_id_distinct". An aggregation over "*" or no field is
+// named after its type alone, e.g. "count".
+func DefaultAggregationAlias(agg AggregationConfiguration) string {
+	if agg.Alias != "" {
+		return agg.Alias
+	}
+	name := string(agg.Type)
+	if agg.Field != "" && agg.Field != "*" {
+		name += "_" + agg.Field
+	}
+	if agg.Distinct {
+		name += ":distinct"
+	}
+	return name
+}
+
+// ValidateAggregationAliases returns an error when two aggregations of a
+// QueryDSL surface under the same name (see DefaultAggregationAlias), e.g. a
+// COUNT(*) and a COUNT(DISTINCT ...) given the same alias, since only one of
+// them could appear in the result rows.
+func ValidateAggregationAliases(aggregations []AggregationConfiguration) error {
+	seen := make(map[string]struct{}, len(aggregations))
+	for _, agg := range aggregations {
+		alias := DefaultAggregationAlias(agg)
+		if _, dup := seen[alias]; dup {
+			return fmt.Errorf("duplicate aggregation alias: %s", alias)
+		}
+		seen[alias] = struct{}{}
+	}
+	return nil
+}
+
+// ExtremeRow returns the row with the largest (max true) or smallest value of
+// field, comparing values like the generated SQL and skipping NULLs,
+// including driver.Valuer values that report NULL or fail. Ties keep the first
+// row encountered. It is the in-memory counterpart of the argmax and argmin
+// aggregations and returns nil when no row has a non-NULL value.
+func ExtremeRow(rows []Row, field string, max bool) Row {
+	var best Row
+	var bestValue any
+	for _, row := range rows {
+		value, err := driverValue(row[field])
+		if err != nil || value == nil {
+			continue
+		}
+		if best == nil {
+			best, bestValue = row, value
+			continue
+		}
+		c := compareValues(value, bestValue)
+		if (max && c > 0) || (!max && c < 0) {
+			best, bestValue = row, value
+		}
+	}
+	return best
+}
+
+// ExtremeRowsBy is the grouped form of ExtremeRow: it returns, for each
+// distinct combination of the key fields, the row with the largest (max true)
+// or smallest value of field, like argmax or argmin with GROUP BY. Keys are
+// compared like the generated SQL groups them, so NULL keys form one group.
+// Groups are returned in the order their first row appears, and groups in
+// which field is NULL on every row are omitted.
+func ExtremeRowsBy(rows []Row, field string, max bool, keys ...string) ([]Row, error) {
+	type extremeGroup struct {
+		key  []any
+		rows []Row
+	}
+	var groups []*extremeGroup
+	for _, row := range rows {
+		key := make([]any, len(keys))
+		for i, k := range keys {
+			value, err := driverValue(row[k])
+			if err != nil {
+				return nil, fmt.Errorf("group key %s: %w", k, err)
+			}
+			key[i] = value
+		}
+		var g *extremeGroup
+		for _, candidate := range groups {
+			if sameKey(candidate.key, key) {
+				g = candidate
+				break
+			}
+		}
+		if g == nil {
+			g = &extremeGroup{key: key}
+			groups = append(groups, g)
+		}
+		g.rows = append(g.rows, row)
+	}
+
+	result := make([]Row, 0, len(groups))
+	for _, g := range groups {
+		if best := ExtremeRow(g.rows, field, max); best != nil {
+			result = append(result, best)
+		}
+	}
+	return result, nil
+}
+
+func sameKey(a, b []any) bool {
+	for i := range a {
+		if !isSame(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
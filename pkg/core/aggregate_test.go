@@ -0,0 +1,172 @@
+package core
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeAggregateValue(t *testing.T) {
+	tests := []struct {
+		aggType AggregationType
+		value   any
+		want    any
+	}{
+		{AggregationTypeCount, int64(3), int64(3)},
+		{AggregationTypeCount, 3, int64(3)},
+		{AggregationTypeCount, uint32(3), int64(3)},
+		{AggregationTypeCount, 3.0, int64(3)},
+		{AggregationTypeCount, "3", int64(3)},
+		{AggregationTypeSum, int64(math.MaxInt64), int64(math.MaxInt64)},
+		{AggregationTypeSum, int64(9007199254740993), int64(9007199254740993)},
+		{AggregationTypeSum, "9007199254740993", int64(9007199254740993)},
+		{AggregationTypeSum, []byte("-12"), int64(-12)},
+		{AggregationTypeSum, 12.0, 12.0},
+		{AggregationTypeSum, "12.5", 12.5},
+		{AggregationTypeSum, "1e3", 1000.0},
+		{AggregationTypeSum, uint64(math.MaxUint64), float64(math.MaxUint64)},
+		{AggregationTypeAvg, int64(2), 2.0},
+		{AggregationTypeAvg, "2.5", 2.5},
+		{AggregationTypeMin, "abc", "abc"},
+		{AggregationTypeMax, int32(7), int32(7)},
+		{AggregationTypeSum, nil, nil},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeAggregateValue(tt.aggType, tt.value)
+		if err != nil {
+			t.Errorf("%s(%#v): %v", tt.aggType, tt.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s(%#v) = %#v, want %#v", tt.aggType, tt.value, got, tt.want)
+		}
+	}
+
+	if _, err := NormalizeAggregateValue(AggregationTypeSum, "abc"); err == nil {
+		t.Error("non-numeric text: expected an error")
+	}
+	for _, count := range []any{3.7, "3.5", math.Inf(1), math.NaN()} {
+		if _, err := NormalizeAggregateValue(AggregationTypeCount, count); err == nil {
+			t.Errorf("non-integral count %v: expected an error", count)
+		}
+	}
+	if _, err := NormalizeAggregateValue(AggregationTypeCount, struct{}{}); err == nil {
+		t.Error("unexpected type: expected an error")
+	}
+}
+
+func TestValidateAggregationAliases(t *testing.T) {
+	count := AggregationConfiguration{Type: AggregationTypeCount, Field: "user_id"}
+	distinct := AggregationConfiguration{Type: AggregationTypeCount, Field: "user_id", Distinct: true}
+	if got := DefaultAggregationAlias(count); got != "count_user_id" {
+		t.Errorf("default alias: got %q", got)
+	}
+	if got := DefaultAggregationAlias(distinct); got != "count_user_id:distinct" {
+		t.Errorf("default distinct alias: got %q", got)
+	}
+	if got := DefaultAggregationAlias(AggregationConfiguration{Type: AggregationTypeCount, Field: "*"}); got != "count" {
+		t.Errorf("count(*) alias: got %q", got)
+	}
+
+	tests := []struct {
+		name         string
+		aggregations []AggregationConfiguration
+		wantErr      bool
+	}{
+		{"count and distinct count", []AggregationConfiguration{count, distinct}, false},
+		{"count of distinct_user_id and distinct count", []AggregationConfiguration{{Type: AggregationTypeCount, Field: "distinct_user_id"}, distinct}, false},
+		{"count of user_id_distinct and distinct count", []AggregationConfiguration{{Type: AggregationTypeCount, Field: "user_id_distinct"}, distinct}, false},
+		{"same default twice", []AggregationConfiguration{count, count}, true},
+		{"explicit alias collides with default", []AggregationConfiguration{count, {Type: AggregationTypeSum, Field: "total", Alias: "count_user_id"}}, true},
+		{"explicit aliases", []AggregationConfiguration{{Type: AggregationTypeCount, Field: "*", Alias: "orders"}, {Type: AggregationTypeCount, Field: "user_id", Distinct: true, Alias: "buyers"}}, false},
+		{"same explicit alias", []AggregationConfiguration{{Type: AggregationTypeCount, Alias: "n"}, {Type: AggregationTypeCount, Distinct: true, Field: "user_id", Alias: "n"}}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateAggregationAliases(tt.aggregations); (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestOrdersByProductCounts(t *testing.T) {
+	orders := []Row{
+		{"id": int64(1), "product_id": int64(7), "user_id": int64(10)},
+		{"id": int64(2), "product_id": int64(7), "user_id": int64(10)},
+		{"id": int64(3), "product_id": int64(7), "user_id": int64(11)},
+		{"id": int64(4), "product_id": int64(8), "user_id": int64(12)},
+	}
+	product := cond("product_id", ComparisonOperatorEq, 7)
+	dsl := &QueryDSL{
+		Filters: &product,
+		Aggregations: []AggregationConfiguration{
+			{Type: AggregationTypeCount, Field: "*"},
+			{Type: AggregationTypeCount, Field: "user_id", Distinct: true},
+		},
+	}
+	if err := ValidateAggregationAliases(dsl.Aggregations); err != nil {
+		t.Fatalf("raw and distinct counts of one query: %v", err)
+	}
+
+	// Compute what the database would return for each aggregation, keyed by
+	// the name it surfaces under.
+	rows, err := FilterRows(orders, dsl.Filters, EvalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := make(Row)
+	for _, agg := range dsl.Aggregations {
+		var count int64
+		seen := make(map[any]struct{})
+		for _, row := range rows {
+			if agg.Distinct {
+				if _, dup := seen[row[agg.Field]]; dup {
+					continue
+				}
+				seen[row[agg.Field]] = struct{}{}
+			}
+			count++
+		}
+		result[DefaultAggregationAlias(agg)] = count
+	}
+	want := Row{"count": int64(3), "count_user_id:distinct": int64(2)}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("got %v, want %v", result, want)
+	}
+}
+
+func TestExtremeRowsBy(t *testing.T) {
+	users := []Row{
+		{"id": int64(1), "region": "eu", "balance": int64(300)},
+		{"id": int64(2), "region": "us", "balance": int64(150)},
+		{"id": int64(3), "region": "eu", "balance": int64(900)},
+		{"id": int64(4), "region": nil, "balance": int64(50)},
+		{"id": int64(5), "region": "us", "balance": nil},
+		{"id": int64(6), "region": "us", "balance": int64(700)},
+		{"id": int64(7), "region": nil, "balance": int64(80)},
+		{"id": int64(8), "region": "asia", "balance": nil},
+	}
+	top, err := ExtremeRowsBy(users, "balance", true, "region")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Row{users[2], users[5], users[6]}
+	if !reflect.DeepEqual(top, want) {
+		t.Errorf("top balance per region: got %v, want %v", top, want)
+	}
+
+	bottom, err := ExtremeRowsBy(users, "balance", false, "region")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{1, 2, 4}; !equalIDs(ids(bottom), want) {
+		t.Errorf("lowest balance per region: got ids %v, want %v", ids(bottom), want)
+	}
+
+	all, err := ExtremeRowsBy(users, "balance", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{3}; !equalIDs(ids(all), want) {
+		t.Errorf("no keys: got ids %v, want %v", ids(all), want)
+	}
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrCursorMismatch is returned when a pagination cursor is used with a query
+// sorted differently from the one that produced it.
+var ErrCursorMismatch = errors.New("cursor does not match the query's sort configuration")
+
+// cursorPayload is the encoded content of a pagination cursor.
+type cursorPayload struct {
+	SortHash string `json:"s"` // Hash of the sort configuration the cursor was produced for
+	Keys     []any  `json:"k"` // Sort-key values of the last row of the page
+}
+
+// EncodeCursor builds an opaque cursor from the sort-key values of the last
+// row of a page. The cursor embeds a hash of the sort configuration so that
+// it can be validated against the query it is later used with, which keeps
+// pagination stateless across a load-balanced backend.
+func EncodeCursor(sort []SortConfiguration, keys []any) (string, error) {
+	data, err := json.Marshal(cursorPayload{SortHash: sortHash(sort), Keys: keys})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor returns the sort-key values stored in a cursor produced by
+// EncodeCursor. It returns ErrCursorMismatch when the cursor was produced for
+// a different sort configuration. Integer keys decode as int64, keeping ids
+// beyond 2^53 exact, and other numeric keys as float64.
+func DecodeCursor(cursor string, sort []SortConfiguration) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	var payload cursorPayload
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if payload.SortHash != sortHash(sort) {
+		return nil, ErrCursorMismatch
+	}
+	for i, key := range payload.Keys {
+		n, ok := key.(json.Number)
+		if !ok {
+			continue
+		}
+		if v, err := n.Int64(); err == nil {
+			payload.Keys[i] = v
+		} else if v, err := n.Float64(); err == nil {
+			payload.Keys[i] = v
+		} else {
+			return nil, fmt.Errorf("invalid cursor: bad numeric key %s", n)
+		}
+	}
+	return payload.Keys, nil
+}
+
+// sortHash returns a short, stable hash of the parts of a sort configuration
+// that affect row order.
+func sortHash(sort []SortConfiguration) string {
+	var b strings.Builder
+	for _, s := range sort {
+		direction := s.Direction
+		if direction == "" {
+			direction = SortDirectionAsc
+		}
+		fmt.Fprintf(&b, "%q %s %s %s", s.Field, direction, s.Collation, s.Cast)
+		if s.Case != nil {
+			caseJSON, _ := json.Marshal(s.Case)
+			b.Write(caseJSON)
+		}
+		b.WriteByte(';')
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	sort := []SortConfiguration{{Field: "score", Direction: SortDirectionDesc}, {Field: "id", Direction: SortDirectionAsc}}
+	keys := []any{2.5, int64(math.MaxInt64), "name", nil, true}
+	cursor, err := EncodeCursor(sort, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodeCursor(cursor, sort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, keys) {
+		t.Errorf("decoded %#v, want %#v", got, keys)
+	}
+}
+
+func TestDecodeCursorRejectsMismatch(t *testing.T) {
+	sort := []SortConfiguration{{Field: "id", Direction: SortDirectionAsc}}
+	cursor, err := EncodeCursor(sort, []any{int64(10)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	others := [][]SortConfiguration{
+		{{Field: "id", Direction: SortDirectionDesc}},
+		{{Field: "created_at", Direction: SortDirectionAsc}},
+		{{Field: "id", Direction: SortDirectionAsc, Collation: CollationNoCase}},
+		{{Field: "id", Direction: SortDirectionAsc}, {Field: "name", Direction: SortDirectionAsc}},
+		nil,
+	}
+	for _, other := range others {
+		if _, err := DecodeCursor(cursor, other); !errors.Is(err, ErrCursorMismatch) {
+			t.Errorf("sort %+v: got %v, want ErrCursorMismatch", other, err)
+		}
+	}
+	// An omitted direction means ascending and produces the same cursor.
+	if _, err := DecodeCursor(cursor, []SortConfiguration{{Field: "id"}}); err != nil {
+		t.Errorf("default direction: unexpected error %v", err)
+	}
+	if _, err := DecodeCursor("not a cursor!", sort); err == nil || errors.Is(err, ErrCursorMismatch) {
+		t.Errorf("malformed cursor: got %v, want a decoding error", err)
+	}
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrTableNotFound is returned by executors when the queried table does not
+// exist, so callers can tell a missing resource apart from other failures
+// using errors.As.
+type ErrTableNotFound struct {
+	Table string // The table that was queried
+}
+
+func (e ErrTableNotFound) Error() string {
+	return fmt.Sprintf("table not found: %s", e.Table)
+}
+
+// ErrOperatorNotAllowed is returned when a filter uses an operator outside
+// the set an executor has been configured to allow.
+type ErrOperatorNotAllowed struct {
+	Operator ComparisonOperator // The forbidden operator
+	Field    string             // The field it was applied to
+}
+
+func (e ErrOperatorNotAllowed) Error() string {
+	return fmt.Sprintf("operator %q is not allowed (field %q)", e.Operator, e.Field)
+}
+
+// ErrUniqueViolation is returned when a write violates a UNIQUE or PRIMARY KEY constraint.
+type ErrUniqueViolation struct {
+	Columns []string // The columns covered by the constraint, when known
+	Err     error    // The underlying driver error
+}
+
+func (e ErrUniqueViolation) Error() string {
+	if len(e.Columns) == 0 {
+		return "unique constraint violated"
+	}
+	return fmt.Sprintf("unique constraint violated on %s", strings.Join(e.Columns, ", "))
+}
+
+func (e ErrUniqueViolation) Unwrap() error { return e.Err }
+
+// ErrNotNullViolation is returned when a write stores NULL in a NOT NULL column.
+type ErrNotNullViolation struct {
+	Column string // The offending column, when known
+	Err    error  // The underlying driver error
+}
+
+func (e ErrNotNullViolation) Error() string {
+	if e.Column == "" {
+		return "not null constraint violated"
+	}
+	return fmt.Sprintf("not null constraint violated on %s", e.Column)
+}
+
+func (e ErrNotNullViolation) Unwrap() error { return e.Err }
+
+// ErrForeignKeyViolation is returned when a write violates a FOREIGN KEY constraint.
+type ErrForeignKeyViolation struct {
+	Err error // The underlying driver error
+}
+
+func (e ErrForeignKeyViolation) Error() string {
+	return "foreign key constraint violated"
+}
+
+func (e ErrForeignKeyViolation) Unwrap() error { return e.Err }
+
+// ErrCheckViolation is returned when a write violates a CHECK constraint.
+type ErrCheckViolation struct {
+	Constraint string // The constraint name, when known
+	Err        error  // The underlying driver error
+}
+
+func (e ErrCheckViolation) Error() string {
+	if e.Constraint == "" {
+		return "check constraint violated"
+	}
+	return fmt.Sprintf("check constraint violated: %s", e.Constraint)
+}
+
+func (e ErrCheckViolation) Unwrap() error { return e.Err }
+
+// ErrPolicyViolation is returned when a QueryPolicy rejects a query.
+type ErrPolicyViolation struct {
+	Policy string // Name of the policy that rejected the query
+	Reason string // Human-readable explanation
+}
+
+func (e ErrPolicyViolation) Error() string {
+	return fmt.Sprintf("query rejected by policy %s: %s", e.Policy, e.Reason)
+}
+
+// ErrStaleVersion is returned by an optimistic-concurrency update when no row
+// matched the expected version, meaning another writer updated it first.
+type ErrStaleVersion struct {
+	ExpectedVersion any // The version the caller expected the row to have
+}
+
+func (e ErrStaleVersion) Error() string {
+	return fmt.Sprintf("stale version: no row matched version %v", e.ExpectedVersion)
+}
+
+// ErrUnboundParam is returned when a QueryDSL references a Param that was
+// not supplied at execution time.
+type ErrUnboundParam struct {
+	Name string // The parameter name
+}
+
+func (e ErrUnboundParam) Error() string {
+	return fmt.Sprintf("unbound parameter: %s", e.Name)
+}
+
+// ErrUnsupportedFeature is returned when a query needs a feature the
+// underlying database build does not provide, instead of letting it fail
+// later with a cryptic syntax error.
+type ErrUnsupportedFeature struct {
+	Feature string // e.g. "UPDATE ... LIMIT" or "JSON1"
+}
+
+func (e ErrUnsupportedFeature) Error() string {
+	return fmt.Sprintf("feature unsupported by this database build: %s", e.Feature)
+}
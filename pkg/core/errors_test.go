@@ -0,0 +1,30 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConstraintViolationErrors(t *testing.T) {
+	driverErr := errors.New("constraint failed")
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{ErrUniqueViolation{Columns: []string{"tenant_id", "email"}, Err: driverErr}, "unique constraint violated on tenant_id, email"},
+		{ErrUniqueViolation{Err: driverErr}, "unique constraint violated"},
+		{ErrNotNullViolation{Column: "email", Err: driverErr}, "not null constraint violated on email"},
+		{ErrNotNullViolation{Err: driverErr}, "not null constraint violated"},
+		{ErrCheckViolation{Constraint: "positive_balance", Err: driverErr}, "check constraint violated: positive_balance"},
+		{ErrCheckViolation{Err: driverErr}, "check constraint violated"},
+		{ErrForeignKeyViolation{Err: driverErr}, "foreign key constraint violated"},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("%T: got %q, want %q", tt.err, got, tt.want)
+		}
+		if !errors.Is(tt.err, driverErr) {
+			t.Errorf("%T does not unwrap to the driver error", tt.err)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// StandardEvaluator evaluates a standard comparison operator in Go. It
+// receives the row's field value (after casts and field expressions) and the
+// condition's value, either of which may be nil for NULL, and returns the
+// condition's three-valued result: TruthUnknown where SQL would yield NULL.
+type StandardEvaluator func(fieldValue, filterValue any) (Truth, error)
+
+// StandardEvaluators maps standard operators to their Go evaluators. It is
+// passed explicitly through EvalOptions, and executors keep their own copy
+// (see EvaluatorOverrider), so overriding an evaluator never affects other
+// callers in the process.
+type StandardEvaluators map[ComparisonOperator]StandardEvaluator
+
+// defaultEvaluators backs DefaultStandardEvaluators and is never modified.
+var defaultEvaluators = StandardEvaluators{
+	ComparisonOperatorEq:          compareWith(func(c int) bool { return c == 0 }),
+	ComparisonOperatorNeq:         compareWith(func(c int) bool { return c != 0 }),
+	ComparisonOperatorLt:          compareWith(func(c int) bool { return c < 0 }),
+	ComparisonOperatorLte:         compareWith(func(c int) bool { return c <= 0 }),
+	ComparisonOperatorGt:          compareWith(func(c int) bool { return c > 0 }),
+	ComparisonOperatorGte:         compareWith(func(c int) bool { return c >= 0 }),
+	ComparisonOperatorIn:          evaluateIn(true),
+	ComparisonOperatorNin:         evaluateIn(false),
+	ComparisonOperatorContains:    matchText(strings.Contains),
+	ComparisonOperatorNotContains: matchText(func(s, substr string) bool { return !strings.Contains(s, substr) }),
+	ComparisonOperatorStartsWith:  matchText(strings.HasPrefix),
+	ComparisonOperatorEndsWith:    matchText(strings.HasSuffix),
+	ComparisonOperatorExists:      evaluateExists,
+	ComparisonOperatorNotExists:   evaluateNotExists,
+	ComparisonOperatorIs:          evaluateIs,
+	ComparisonOperatorIsNot:       evaluateIsNot,
+	ComparisonOperatorApproxEq:    evaluateApproxEq,
+}
+
+// DefaultStandardEvaluators returns a new map holding the default evaluator
+// of every standard operator, which the caller may override freely.
+func DefaultStandardEvaluators() StandardEvaluators {
+	evaluators := make(StandardEvaluators, len(defaultEvaluators))
+	for op, fn := range defaultEvaluators {
+		evaluators[op] = fn
+	}
+	return evaluators
+}
+
+// Override replaces the evaluator of a standard operator. Custom operators
+// are evaluated by registered Go filter functions instead, so Override
+// rejects an operator that is not standard.
+func (e StandardEvaluators) Override(operator ComparisonOperator, fn StandardEvaluator) error {
+	if !operator.IsStandard() {
+		return fmt.Errorf("%s is not a standard operator; register it as a Go filter function", operator)
+	}
+	if fn == nil {
+		return fmt.Errorf("nil evaluator for operator %s", operator)
+	}
+	e[operator] = fn
+	return nil
+}
+
+// lookup returns the evaluator for a standard operator, falling back to the
+// default when e is nil or has no entry for it.
+func (e StandardEvaluators) lookup(operator ComparisonOperator) (StandardEvaluator, bool) {
+	if fn, ok := e[operator]; ok {
+		return fn, true
+	}
+	fn, ok := defaultEvaluators[operator]
+	return fn, ok
+}
+
+// compareWith builds an evaluator for an ordering comparison. As in SQL, a
+// comparison with NULL on either side is unknown.
+func compareWith(match func(c int) bool) StandardEvaluator {
+	return func(fieldValue, filterValue any) (Truth, error) {
+		if fieldValue == nil || filterValue == nil {
+			return TruthUnknown, nil
+		}
+		return truthOf(match(compareValues(fieldValue, filterValue))), nil
+	}
+}
+
+// evaluateIn builds the in (in == true) and nin evaluators. As in SQL, a NULL
+// field value is unknown, and so is a value that matches no list item when
+// the list contains NULL: NOT IN (25, NULL) never matches. An empty list
+// never matches for in and always matches for nin, as in SQLite.
+func evaluateIn(in bool) StandardEvaluator {
+	return func(fieldValue, filterValue any) (Truth, error) {
+		values, err := toSlice(filterValue)
+		if err != nil {
+			return TruthFalse, err
+		}
+		if len(values) == 0 {
+			return truthOf(!in), nil
+		}
+		if fieldValue == nil {
+			return TruthUnknown, nil
+		}
+		result := TruthFalse
+		for _, v := range values {
+			if v == nil {
+				result = TruthUnknown
+				continue
+			}
+			if compareValues(fieldValue, v) == 0 {
+				result = TruthTrue
+				break
+			}
+		}
+		if !in {
+			return result.Not(), nil
+		}
+		return result, nil
+	}
+}
+
+// matchText builds an evaluator for the LIKE-based operators, which are
+// case-insensitive like SQLite's LIKE. Matching against NULL is unknown.
+func matchText(match func(s, pattern string) bool) StandardEvaluator {
+	return func(fieldValue, filterValue any) (Truth, error) {
+		if fieldValue == nil || filterValue == nil {
+			return TruthUnknown, nil
+		}
+		return truthOf(match(strings.ToLower(toText(fieldValue)), strings.ToLower(toText(filterValue)))), nil
+	}
+}
+
+func evaluateExists(fieldValue, _ any) (Truth, error) {
+	return truthOf(fieldValue != nil), nil
+}
+
+func evaluateNotExists(fieldValue, _ any) (Truth, error) {
+	return truthOf(fieldValue == nil), nil
+}
+
+func evaluateIs(fieldValue, filterValue any) (Truth, error) {
+	return truthOf(isSame(fieldValue, filterValue)), nil
+}
+
+func evaluateIsNot(fieldValue, filterValue any) (Truth, error) {
+	return truthOf(!isSame(fieldValue, filterValue)), nil
+}
+
+// evaluateApproxEq mirrors ABS(field - ?) <= ?: like SQLite arithmetic, it
+// converts a text field value to a number with numericPrefix, so '10' is
+// within 1 of 10.
+func evaluateApproxEq(fieldValue, filterValue any) (Truth, error) {
+	target, epsilon, err := approxOperands(filterValue)
+	if err != nil {
+		return TruthFalse, err
+	}
+	if fieldValue == nil {
+		return TruthUnknown, nil
+	}
+	n, ok := arithmeticOperand(fieldValue)
+	if !ok {
+		return TruthFalse, nil
+	}
+	f, _ := toFloat(n)
+	return truthOf(math.Abs(f-target) <= epsilon), nil
+}
+
+// approxOperands unpacks the [value, epsilon] pair of an approxeq condition.
+func approxOperands(value any) (target, epsilon float64, err error) {
+	values, err := toSlice(value)
+	if err != nil || len(values) != 2 {
+		return 0, 0, fmt.Errorf("expected a [value, epsilon] pair, got %v", value)
+	}
+	target, ok1 := toFloat(values[0])
+	epsilon, ok2 := toFloat(values[1])
+	if !ok1 || !ok2 {
+		return 0, 0, fmt.Errorf("expected numeric [value, epsilon], got %v", value)
+	}
+	return target, epsilon, nil
+}
+
+// isSame implements NULL-safe equality: two NULLs are the same, and NULL is
+// never the same as a non-NULL value.
+func isSame(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return compareValues(a, b) == 0
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDefaultStandardEvaluators(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		op          ComparisonOperator
+		field, with any
+		want        Truth
+	}{
+		{ComparisonOperatorEq, int64(25), 25, TruthTrue},
+		{ComparisonOperatorEq, int64(25), 25.0, TruthTrue},
+		{ComparisonOperatorEq, uint8(1), true, TruthTrue},
+		{ComparisonOperatorEq, "25", 25, TruthFalse},
+		{ComparisonOperatorEq, []byte("a"), []byte("a"), TruthTrue},
+		{ComparisonOperatorEq, now, now, TruthTrue},
+		{ComparisonOperatorEq, nil, 25, TruthUnknown},
+		{ComparisonOperatorEq, 25, nil, TruthUnknown},
+		{ComparisonOperatorNeq, int64(25), 26, TruthTrue},
+		{ComparisonOperatorNeq, nil, 26, TruthUnknown},
+		{ComparisonOperatorLt, int64(9007199254740992), int64(9007199254740993), TruthTrue},
+		{ComparisonOperatorLt, 3, "1", TruthTrue},
+		{ComparisonOperatorLte, 2.5, 2.5, TruthTrue},
+		{ComparisonOperatorGt, "b", "a", TruthTrue},
+		{ComparisonOperatorGt, []byte("a"), "z", TruthTrue},
+		{ComparisonOperatorGte, now, now.Add(-time.Second), TruthTrue},
+		{ComparisonOperatorGte, nil, nil, TruthUnknown},
+		{ComparisonOperatorIn, int64(2), []int{1, 2}, TruthTrue},
+		{ComparisonOperatorIn, int64(3), []any{1, nil}, TruthUnknown},
+		{ComparisonOperatorIn, int64(1), []any{1, nil}, TruthTrue},
+		{ComparisonOperatorIn, nil, []any{1}, TruthUnknown},
+		{ComparisonOperatorIn, nil, []any{}, TruthFalse},
+		{ComparisonOperatorNin, int64(3), []string{"3"}, TruthTrue},
+		{ComparisonOperatorNin, int64(3), []any{1, nil}, TruthUnknown},
+		{ComparisonOperatorNin, int64(1), []any{1, nil}, TruthFalse},
+		{ComparisonOperatorNin, nil, []any{}, TruthTrue},
+		{ComparisonOperatorContains, "Hello", "ELL", TruthTrue},
+		{ComparisonOperatorContains, int64(1234), "23", TruthTrue},
+		{ComparisonOperatorContains, nil, "a", TruthUnknown},
+		{ComparisonOperatorNotContains, "Hello", "xyz", TruthTrue},
+		{ComparisonOperatorNotContains, nil, "a", TruthUnknown},
+		{ComparisonOperatorStartsWith, "Hello", "he", TruthTrue},
+		{ComparisonOperatorEndsWith, []byte("file.TXT"), ".txt", TruthTrue},
+		{ComparisonOperatorExists, int64(0), nil, TruthTrue},
+		{ComparisonOperatorExists, nil, nil, TruthFalse},
+		{ComparisonOperatorNotExists, nil, nil, TruthTrue},
+		{ComparisonOperatorNotExists, "", nil, TruthFalse},
+		{ComparisonOperatorIs, nil, nil, TruthTrue},
+		{ComparisonOperatorIs, int64(1), nil, TruthFalse},
+		{ComparisonOperatorIs, int64(1), 1.0, TruthTrue},
+		{ComparisonOperatorIsNot, nil, 1, TruthTrue},
+		{ComparisonOperatorIsNot, nil, nil, TruthFalse},
+		{ComparisonOperatorApproxEq, 0.1 + 0.2, []any{0.3, 1e-9}, TruthTrue},
+		{ComparisonOperatorApproxEq, int64(10), []float64{9, 0.5}, TruthFalse},
+		{ComparisonOperatorApproxEq, "10", []any{10, 1}, TruthTrue},
+		{ComparisonOperatorApproxEq, "10.4kg", []any{10, 0.5}, TruthTrue},
+		{ComparisonOperatorApproxEq, "abc", []any{10, 1}, TruthFalse},
+		{ComparisonOperatorApproxEq, "abc", []any{0, 0}, TruthTrue},
+		{ComparisonOperatorApproxEq, nil, []any{10, 1}, TruthUnknown},
+	}
+	evaluators := DefaultStandardEvaluators()
+	for op := range GetStandardComparisonOperators() {
+		if _, ok := evaluators[op]; !ok {
+			t.Errorf("no default evaluator for %s", op)
+		}
+	}
+	for _, tt := range tests {
+		got, err := evaluators[tt.op](tt.field, tt.with)
+		if err != nil {
+			t.Errorf("%s(%v, %v): %v", tt.op, tt.field, tt.with, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s(%v, %v) = %v, want %v", tt.op, tt.field, tt.with, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultStandardEvaluatorsErrors(t *testing.T) {
+	evaluators := DefaultStandardEvaluators()
+	if _, err := evaluators[ComparisonOperatorIn](int64(1), 1); err == nil {
+		t.Error("in with a scalar value: expected an error")
+	}
+	if _, err := evaluators[ComparisonOperatorApproxEq](1.0, []any{1.0}); err == nil {
+		t.Error("approxeq without epsilon: expected an error")
+	}
+	if _, err := evaluators[ComparisonOperatorApproxEq](1.0, []any{"a", 1}); err == nil {
+		t.Error("approxeq with text target: expected an error")
+	}
+}
+
+func TestStandardEvaluatorsOverride(t *testing.T) {
+	evaluators := DefaultStandardEvaluators()
+	caseSensitive := func(fieldValue, filterValue any) (Truth, error) {
+		s, _ := fieldValue.(string)
+		sub, _ := filterValue.(string)
+		return truthOf(len(sub) <= len(s) && s[:len(sub)] == sub), nil
+	}
+	if err := evaluators.Override(ComparisonOperatorStartsWith, caseSensitive); err != nil {
+		t.Fatal(err)
+	}
+	if err := evaluators.Override("fuzzy", caseSensitive); err == nil {
+		t.Error("overriding a custom operator: expected an error")
+	}
+	if err := evaluators.Override(ComparisonOperatorEq, nil); err == nil {
+		t.Error("overriding with a nil evaluator: expected an error")
+	}
+
+	filter := cond("name", ComparisonOperatorStartsWith, "b")
+	rows := []Row{{"id": int64(1), "name": "Bob"}, {"id": int64(2), "name": "bea"}}
+	got, err := FilterRows(rows, &filter, EvalOptions{Evaluators: evaluators})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{2}; !equalIDs(ids(got), want) {
+		t.Errorf("with override: got ids %v, want %v", ids(got), want)
+	}
+
+	// The override is local to its map; the defaults are untouched.
+	got, err = FilterRows(rows, &filter, EvalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{1, 2}; !equalIDs(ids(got), want) {
+		t.Errorf("with defaults: got ids %v, want %v", ids(got), want)
+	}
+}
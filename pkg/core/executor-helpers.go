@@ -0,0 +1,52 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// QueryWithParams binds params to the QueryDSL's Param values (see
+// BindParams) and runs it with exec.Query. It returns ErrUnboundParam when a
+// referenced parameter is missing, without running the query.
+func QueryWithParams(ctx context.Context, exec QueryExecutor, dsl *QueryDSL, params map[string]any) (*QueryResult, error) {
+	bound, err := BindParams(dsl, params)
+	if err != nil {
+		return nil, err
+	}
+	return exec.Query(ctx, bound)
+}
+
+// QueryChanges runs the QueryDSL with exec.Query, restricted to rows whose
+// sinceColumn is after since and ordered by sinceColumn ascending, for
+// incremental sync. The QueryDSL's own sort fields follow as tie-breakers.
+// since is bound like any other time.Time filter value, so the executor
+// converts it to the column's stored format. dsl may be nil and is not
+// modified.
+func QueryChanges(ctx context.Context, exec QueryExecutor, sinceColumn string, since time.Time, dsl *QueryDSL) (*QueryResult, error) {
+	var changes QueryDSL
+	if dsl != nil {
+		changes = *dsl
+	}
+	after := &QueryFilter{Condition: &FilterCondition{Field: sinceColumn, Operator: ComparisonOperatorGt, Value: since}}
+	changes.Filters = CombineFilters(LogicalOperatorAnd, changes.Filters, after)
+	changes.Sort = []SortConfiguration{{Field: sinceColumn, Direction: SortDirectionAsc}}
+	if dsl != nil {
+		for _, s := range dsl.Sort {
+			if s.Field != sinceColumn || s.Case != nil {
+				changes.Sort = append(changes.Sort, s)
+			}
+		}
+	}
+	return exec.Query(ctx, &changes)
+}
+
+// Replay re-runs a recorded query from its DSL with exec.Query, e.g. to
+// reproduce a production issue. exec must be bound to the record's Table. It
+// fails if the record or its DSL is nil.
+func Replay(ctx context.Context, exec QueryExecutor, record *QueryRecord) (*QueryResult, error) {
+	if record == nil || record.DSL == nil {
+		return nil, errors.New("query record has no DSL to replay")
+	}
+	return exec.Query(ctx, record.DSL)
+}
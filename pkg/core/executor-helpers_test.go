@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// queryFunc is a QueryExecutor whose Query calls fn; other methods panic.
+type queryFunc struct {
+	QueryExecutor
+	fn func(dsl *QueryDSL) (*QueryResult, error)
+}
+
+func (q queryFunc) Query(_ context.Context, dsl *QueryDSL) (*QueryResult, error) {
+	return q.fn(dsl)
+}
+
+func TestQueryWithParams(t *testing.T) {
+	var ran *QueryDSL
+	exec := queryFunc{fn: func(dsl *QueryDSL) (*QueryResult, error) {
+		ran = dsl
+		return &QueryResult{}, nil
+	}}
+	dsl := &QueryDSL{Filters: ptr(cond("age", ComparisonOperatorGt, Param("min_age")))}
+	if _, err := QueryWithParams(context.Background(), exec, dsl, map[string]any{"min_age": 18}); err != nil {
+		t.Fatal(err)
+	}
+	if ran == nil || ran.Filters.Condition.Value != 18 {
+		t.Errorf("ran %+v, want min_age bound to 18", ran)
+	}
+
+	ran = nil
+	_, err := QueryWithParams(context.Background(), exec, dsl, nil)
+	if !errors.As(err, new(ErrUnboundParam)) || ran != nil {
+		t.Errorf("missing param: got err %v and ran %v, want ErrUnboundParam without running", err, ran)
+	}
+}
+
+func TestQueryChanges(t *testing.T) {
+	var ran *QueryDSL
+	exec := queryFunc{fn: func(dsl *QueryDSL) (*QueryResult, error) {
+		ran = dsl
+		return &QueryResult{}, nil
+	}}
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	scope := cond("tenant_id", ComparisonOperatorEq, 7)
+	dsl := &QueryDSL{
+		Filters: &scope,
+		Sort:    []SortConfiguration{{Field: "updated_at", Direction: SortDirectionDesc}, {Field: "id", Direction: SortDirectionAsc}},
+	}
+	if _, err := QueryChanges(context.Background(), exec, "updated_at", since, dsl); err != nil {
+		t.Fatal(err)
+	}
+	wantFilters := group(LogicalOperatorAnd, scope, cond("updated_at", ComparisonOperatorGt, since))
+	if !reflect.DeepEqual(*ran.Filters, wantFilters) {
+		t.Errorf("filters: got %+v, want %+v", ran.Filters, wantFilters)
+	}
+	wantSort := []SortConfiguration{{Field: "updated_at", Direction: SortDirectionAsc}, {Field: "id", Direction: SortDirectionAsc}}
+	if !reflect.DeepEqual(ran.Sort, wantSort) {
+		t.Errorf("sort: got %+v, want %+v", ran.Sort, wantSort)
+	}
+	if dsl.Filters != &scope || dsl.Sort[0].Direction != SortDirectionDesc {
+		t.Error("QueryChanges modified its input")
+	}
+
+	if _, err := QueryChanges(context.Background(), exec, "updated_at", since, nil); err != nil {
+		t.Fatal(err)
+	}
+	if ran.Filters.Condition == nil || ran.Filters.Condition.Field != "updated_at" {
+		t.Errorf("nil QueryDSL: got filters %+v", ran.Filters)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	var ran *QueryDSL
+	exec := queryFunc{fn: func(dsl *QueryDSL) (*QueryResult, error) {
+		ran = dsl
+		return &QueryResult{}, nil
+	}}
+	record := &QueryRecord{Table: "users", DSL: &QueryDSL{Filters: ptr(cond("id", ComparisonOperatorEq, 1))}}
+	if _, err := Replay(context.Background(), exec, record); err != nil {
+		t.Fatal(err)
+	}
+	if ran != record.DSL {
+		t.Errorf("replayed %+v, want the recorded DSL", ran)
+	}
+	if _, err := Replay(context.Background(), exec, &QueryRecord{Table: "users"}); err == nil {
+		t.Error("record without DSL: expected an error")
+	}
+}
@@ -2,6 +2,9 @@ package core
 
 import (
 	"context"
+	"database/sql"
+	"io"
+	"time"
 )
 
 // Row represents a single record/row of data retrieved from the database.
@@ -13,13 +16,93 @@ type Row map[string]any
 // for a new field, and an error if computation fails.
 type GoComputeFunction func(row Row) (any, error)
 
+// GoMultiComputeFunction is a pure Go function that computes several related
+// values for a row. The keys of the returned map are merged into the row as
+// new fields, so a single function can add multiple computed fields.
+type GoMultiComputeFunction func(row Row) (map[string]any, error)
+
+// GoFieldTransformFunction transforms the value of a single projected field,
+// e.g. to mask a phone number or round a price. It is lighter than a
+// GoComputeFunction since it only sees the field's own value.
+type GoFieldTransformFunction func(value any) (any, error)
+
+// GoScoreFunction computes a relevance score for a row that passed the
+// filters (PipelineStageScore), e.g. the number of search terms it matches.
+type GoScoreFunction func(row Row) (float64, error)
+
+// GoBatchFilterFunction performs custom filtering over all candidate rows at
+// once, e.g. to do a single bulk lookup against an external service. It
+// returns a slice parallel to rows, where true means the row passes.
+type GoBatchFilterFunction func(rows []Row) ([]bool, error)
+
+// GoWindowComputeFunction is a stateful Go function applied over the sorted
+// result set (PipelineStageWindowCompute), e.g. to compute a running balance.
+// It receives the value it returned for the previous row (nil for the first
+// row) and returns the value for the current row, which is then passed to the
+// next invocation.
+type GoWindowComputeFunction func(prev any, row Row) (any, error)
+
 // GoFilterFunction is a pure Go function that performs custom filtering logic on a row.
 // It takes a Row and returns true if the row passes the filter, false otherwise,
 // and an error if evaluation fails.
 type GoFilterFunction func(row Row) (bool, error)
 
+// GoRowTransformFunction is a Go function applied to every row after compute
+// functions and before the final projection (PipelineStageRowTransform), for
+// uniform post-processing such as rounding, redaction or renaming fields.
+// Returning an error aborts the query.
+type GoRowTransformFunction func(row Row) (Row, error)
+
+// ScannerFactory returns a fresh scan destination for a column, letting
+// executors scan known columns into caller-chosen types (sql.NullString or a
+// custom sql.Scanner) instead of the generic any. The scanner itself is
+// stored as the column's value in the resulting Row.
+type ScannerFactory func() sql.Scanner
+
+// TableResolver maps a logical table name to the physical table to query for
+// the current request, e.g. appending a tenant suffix read from ctx. Executors
+// still quote the resolved name as an identifier.
+type TableResolver func(ctx context.Context, logicalName string) (physicalName string, err error)
+
+// QueryRecord captures one executed query for debugging and analysis.
+type QueryRecord struct {
+	Table     string    // The table the query ran against
+	DSL       *QueryDSL // The query as received
+	SQL       string    // The generated SQL
+	Params    []any     // Bound parameters; nil when Redacted
+	Redacted  bool      // The executor was configured to redact parameter values
+	Timestamp time.Time // When the query was executed
+	RowCount  int       // Number of rows returned
+}
+
+// QueryRecorder is a pluggable sink that receives a QueryRecord for every
+// query an executor runs while recording is enabled. Redaction of parameter
+// values is an executor setting applied before Record is called, so
+// recorders never see redacted values.
+type QueryRecorder interface {
+	Record(ctx context.Context, record QueryRecord)
+}
+
+// Capabilities records which optional database features are available, as
+// probed by an executor at construction. Features that depend on them either
+// fall back to Go-side evaluation or return ErrUnsupportedFeature.
+type Capabilities struct {
+	JSON            bool // JSON functions such as json_each (SQLite's JSON1)
+	WindowFunctions bool // OVER (...) window functions
+	Returning       bool // RETURNING clauses on INSERT/UPDATE/DELETE
+	UpdateFrom      bool // UPDATE ... FROM
+	UpdateLimit     bool // UPDATE/DELETE ... ORDER BY ... LIMIT
+	FilterClause    bool // Aggregate FILTER (WHERE ...) clauses
+}
+
 // QueryExecutor defines the interface for executing queries against a database
 // using a QueryDSL object, and applying Go-based logic post-retrieval.
+//
+// Features beyond this core set are optional and exposed through the small
+// interfaces below (FunctionRegistry, Preparer, UnionQuerier, ...), which
+// callers detect with a type assertion. Helpers that only need Query, such as
+// QueryWithParams, QueryChanges and Replay, are package-level functions that
+// work with any QueryExecutor.
 type QueryExecutor interface {
 	// RegisterComputeFunction registers a single GoComputeFunction
 	// under a specific name. This name will be used in the QueryDSL's FunctionCall
@@ -62,3 +145,175 @@ type QueryExecutor interface {
 	// It returns the final processed results and any associated metadata.
 	Query(ctx context.Context, dsl *QueryDSL) (*QueryResult, error)
 }
+
+// FunctionRegistry is implemented by executors that support Go functions
+// beyond per-row compute and filter functions.
+type FunctionRegistry interface {
+	// RegisterMultiComputeFunction registers a GoMultiComputeFunction under a
+	// specific name. It is referenced like a GoComputeFunction from a
+	// ComputedFieldExpression, but the expression's Alias is ignored and every
+	// key the function returns is added to the row and kept by the projection.
+	RegisterMultiComputeFunction(name string, fn GoMultiComputeFunction)
+
+	// RegisterWindowComputeFunction registers a GoWindowComputeFunction under
+	// a specific name, referenced like a GoComputeFunction from a
+	// ComputedFieldExpression. It is applied after sorting, in row order.
+	RegisterWindowComputeFunction(name string, fn GoWindowComputeFunction)
+
+	// RegisterBatchFilterFunction registers a GoBatchFilterFunction under a
+	// specific comparison operator name. When both a batch and a per-row
+	// function are registered for an operator, the batch function is used.
+	RegisterBatchFilterFunction(operator ComparisonOperator, fn GoBatchFilterFunction)
+
+	// RegisterFieldTransform registers a GoFieldTransformFunction under a
+	// specific name, referenced from a ProjectionField's Transform.
+	RegisterFieldTransform(name string, fn GoFieldTransformFunction)
+
+	// RegisterScoreFunction registers a GoScoreFunction under a specific name,
+	// referenced from a QueryDSL's Score configuration.
+	RegisterScoreFunction(name string, fn GoScoreFunction)
+}
+
+// FunctionIntrospector is implemented by executors that can list their
+// registered Go functions.
+type FunctionIntrospector interface {
+	// RegisteredComputeFunctions returns, sorted, the names a
+	// ComputedFieldExpression can reference, e.g. to validate a QueryDSL
+	// before execution or to build a catalog for clients. It covers single,
+	// multi and window compute functions, which share one namespace; field
+	// transforms and score functions are not included.
+	RegisteredComputeFunctions() []string
+
+	// RegisteredFilterOperators returns, sorted, the custom operators a
+	// FilterCondition can use: those with a per-row filter function, a batch
+	// filter function, or both, each listed once.
+	RegisteredFilterOperators() []ComparisonOperator
+}
+
+// VersionedUpdater is implemented by executors configured with a version
+// column for optimistic concurrency.
+type VersionedUpdater interface {
+	// UpdateIf performs an optimistic-concurrency update: it adds a check that
+	// the executor's version column equals expectedVersion to the filters and
+	// increments the version column in the same statement. It returns
+	// ErrStaleVersion when no row is affected because another writer updated
+	// the row first.
+	UpdateIf(ctx context.Context, updates map[string]any, filters QueryFilter, expectedVersion any) (int64, error)
+}
+
+// FromUpdater is implemented by executors that support UPDATE ... FROM.
+type FromUpdater interface {
+	// UpdateFrom performs an update whose filters may reference the table or
+	// subquery described by source, e.g. to update users based on their orders.
+	// It returns the number of rows affected and an error.
+	UpdateFrom(ctx context.Context, updates map[string]any, source UpdateSource, filters QueryFilter) (int64, error)
+}
+
+// GetOrCreator is implemented by executors that can look up or insert a row
+// atomically.
+type GetOrCreator interface {
+	// GetOrCreate returns the row matching all fields in match (created=false),
+	// or inserts create and returns the inserted row (created=true). It runs in
+	// a transaction; if a concurrent insert wins the race and the insert fails
+	// on a unique constraint, the existing row is fetched and returned instead.
+	GetOrCreate(ctx context.Context, match map[string]any, create map[string]any) (row Row, created bool, err error)
+}
+
+// KeyDeleter is implemented by executors that can report the keys of the
+// rows they delete.
+type KeyDeleter interface {
+	// DeleteReturningKeys deletes the rows matching filters, like Delete with
+	// unsafeDelete false, and returns the keyColumn values of the deleted rows
+	// (e.g. for cache invalidation) instead of whole rows.
+	DeleteReturningKeys(ctx context.Context, filters QueryFilter, keyColumn string) ([]any, error)
+}
+
+// ColumnQuerier is implemented by executors that can return positional rows.
+type ColumnQuerier interface {
+	// QueryColumns runs the QueryDSL like Query but returns the column names in
+	// SELECT order along with positional row values, which preserves column
+	// order for CSV export and avoids building a map per row.
+	QueryColumns(ctx context.Context, dsl *QueryDSL) (cols []string, rows [][]any, err error)
+}
+
+// JSONQuerier is implemented by executors that can stream results as JSON.
+type JSONQuerier interface {
+	// QueryJSON runs the QueryDSL and streams the resulting rows to w as a JSON
+	// array, one row at a time, so memory stays flat for large exports. Values
+	// are normalized as by RowToJSON.
+	QueryJSON(ctx context.Context, dsl *QueryDSL, w io.Writer) error
+}
+
+// StreamQuerier is implemented by executors that can stream result rows.
+type StreamQuerier interface {
+	// QueryChan runs the QueryDSL and streams the resulting rows through a
+	// buffered channel. The row channel is closed when the rows are exhausted;
+	// the error channel then receives at most one error and is closed too.
+	// Cancelling ctx stops the producer and releases its resources, so callers
+	// that stop reading early must cancel ctx to avoid leaking it.
+	QueryChan(ctx context.Context, dsl *QueryDSL) (<-chan Row, <-chan error)
+}
+
+// CountEstimator is implemented by executors that can estimate result sizes.
+type CountEstimator interface {
+	// EstimateCount returns a fast approximate number of rows matching the
+	// QueryDSL's filters, from the database's statistics or query plan, for
+	// "about 1.2M results" displays. The result has Pagination.Estimated set;
+	// executors may fall back to an exact count (Estimated false) for small
+	// tables or when statistics are unavailable.
+	EstimateCount(ctx context.Context, dsl *QueryDSL) (*QueryResult, error)
+}
+
+// RecursiveQuerier is implemented by executors that support WITH RECURSIVE.
+type RecursiveQuerier interface {
+	// QueryRecursive fetches a hierarchy with WITH RECURSIVE: starting from
+	// the rows matching anchor, it repeatedly follows join to collect their
+	// descendants, then applies the QueryDSL to the collected rows.
+	QueryRecursive(ctx context.Context, anchor QueryFilter, join RecursiveJoin, dsl *QueryDSL) (*QueryResult, error)
+}
+
+// UnionQuerier is implemented by executors that can combine selects with UNION.
+type UnionQuerier interface {
+	// QueryUnion runs a UNION of the given branches, with a shared ORDER BY and
+	// LIMIT applied to the combined rows. Branch parameters are bound in order.
+	// It returns an error if the branches do not project matching columns.
+	QueryUnion(ctx context.Context, union *UnionQuery) (*QueryResult, error)
+}
+
+// CapabilityReporter is implemented by executors that probe the database for
+// optional features.
+type CapabilityReporter interface {
+	// Capabilities returns the optional database features detected by the executor.
+	Capabilities() Capabilities
+}
+
+// Preparer is implemented by executors that can prepare queries.
+type Preparer interface {
+	// Prepare generates the SQL for the QueryDSL once and returns a
+	// PreparedQuery that can be run repeatedly with different values for its
+	// Params, avoiding regenerating the SQL for every request with the same
+	// shape.
+	Prepare(dsl *QueryDSL) (PreparedQuery, error)
+}
+
+// EvaluatorOverrider is implemented by executors that let callers replace the
+// Go evaluator of a standard operator, e.g. to make startswith
+// case-sensitive. The override applies to that executor only and, like
+// StandardEvaluators.Override, fails for operators that are not standard.
+type EvaluatorOverrider interface {
+	OverrideStandardEvaluator(operator ComparisonOperator, fn StandardEvaluator) error
+}
+
+// PreparedQuery is a query whose SQL has already been generated.
+type PreparedQuery interface {
+	// Run executes the prepared query with params bound to the Param values
+	// of the prepared QueryDSL by name, as BindParams does, so a Param used in
+	// several places receives the same value in each. Values that are not
+	// Params are fixed when the query is prepared. Run returns
+	// ErrUnboundParam, without executing the query, when a Param is missing
+	// from params.
+	Run(ctx context.Context, params map[string]any) (*QueryResult, error)
+
+	// SQL returns the generated SQL text.
+	SQL() string
+}
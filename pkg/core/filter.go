@@ -0,0 +1,828 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Truth is the outcome of evaluating a filter under SQL's three-valued logic.
+// A comparison involving NULL is neither true nor false but unknown, and a
+// row passes a filter only when the filter evaluates to TruthTrue.
+type Truth int8
+
+const (
+	TruthFalse Truth = iota
+	TruthTrue
+	TruthUnknown
+)
+
+func truthOf(b bool) Truth {
+	if b {
+		return TruthTrue
+	}
+	return TruthFalse
+}
+
+// Not negates t; NOT unknown is still unknown.
+func (t Truth) Not() Truth {
+	switch t {
+	case TruthTrue:
+		return TruthFalse
+	case TruthFalse:
+		return TruthTrue
+	}
+	return TruthUnknown
+}
+
+// EvalOptions supplies the functions FilterRows, EvaluateFilter and SortRows
+// use to evaluate conditions in Go.
+type EvalOptions struct {
+	// Filters evaluates custom operators one row at a time.
+	Filters map[ComparisonOperator]GoFilterFunction
+	// BatchFilters evaluates custom operators over all candidate rows at once.
+	// An operator present in both maps uses its batch function, as executors
+	// do for RegisterBatchFilterFunction.
+	BatchFilters map[ComparisonOperator]GoBatchFilterFunction
+	// Evaluators evaluates standard operators. Operators it has no entry for,
+	// or every operator when it is nil, use DefaultStandardEvaluators.
+	Evaluators StandardEvaluators
+	// BooleanBinding converts boolean condition values, including the items
+	// of in/nin lists, the way the executor binds them as parameters, so
+	// that "is_active eq true" matches a column storing "true" under
+	// BooleanBindingString.
+	BooleanBinding BooleanBinding
+}
+
+// FilterRows evaluates a filter purely in Go against already-loaded rows and
+// returns the rows that pass, e.g. to re-filter a cached result set without
+// querying the database again. Standard operators follow the same semantics
+// as the generated SQL (comparisons with NULL are unknown, LIKE-style
+// operators are case-insensitive, numbers order before text), and custom
+// operators are resolved from opts. Each batch filter function referenced by
+// the filter is called once with all of rows. A nil filter returns rows
+// unchanged.
+func FilterRows(rows []Row, filter *QueryFilter, opts EvalOptions) ([]Row, error) {
+	if filter == nil {
+		return rows, nil
+	}
+	e, err := newRowEvaluator(rows, opts, filter)
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]Row, 0, len(rows))
+	for i, row := range rows {
+		t, err := e.truth(i, row, filter)
+		if err != nil {
+			return nil, err
+		}
+		if t == TruthTrue {
+			filtered = append(filtered, row)
+		}
+	}
+	return filtered, nil
+}
+
+// EvaluateFilter reports whether a single row passes the filter, using the
+// same semantics as FilterRows: the row passes only if the filter is true,
+// not false or unknown. Batch filter functions are called with just this row.
+func EvaluateFilter(row Row, filter *QueryFilter, opts EvalOptions) (bool, error) {
+	t, err := EvaluateFilterTruth(row, filter, opts)
+	return t == TruthTrue, err
+}
+
+// EvaluateFilterTruth evaluates the filter against a single row and returns
+// its three-valued result. Groups combine their conditions the way SQL does:
+// AND is false if any condition is false, OR is true if any condition is
+// true, and otherwise an unknown condition makes the group unknown. NOT and
+// NOR negate the AND and OR of their conditions, and XOR is unknown if any
+// condition is unknown.
+func EvaluateFilterTruth(row Row, filter *QueryFilter, opts EvalOptions) (Truth, error) {
+	e, err := newRowEvaluator([]Row{row}, opts, filter)
+	if err != nil {
+		return TruthFalse, err
+	}
+	return e.truth(0, row, filter)
+}
+
+// rowEvaluator evaluates filters against a fixed set of rows, holding the
+// results of the batch filter functions they reference.
+type rowEvaluator struct {
+	opts    EvalOptions
+	batched map[ComparisonOperator][]bool
+}
+
+// newRowEvaluator runs, once over rows, every batch filter function whose
+// operator appears in filters.
+func newRowEvaluator(rows []Row, opts EvalOptions, filters ...*QueryFilter) (*rowEvaluator, error) {
+	if !opts.BooleanBinding.IsValid() {
+		return nil, fmt.Errorf("unknown boolean binding: %s", opts.BooleanBinding)
+	}
+	e := &rowEvaluator{opts: opts}
+	if len(opts.BatchFilters) == 0 {
+		return e, nil
+	}
+	operators := make(map[ComparisonOperator]struct{})
+	for _, filter := range filters {
+		collectOperators(filter, operators)
+	}
+	for op := range operators {
+		fn, ok := opts.BatchFilters[op]
+		if !ok || op.IsStandard() {
+			continue
+		}
+		results, err := fn(rows)
+		if err != nil {
+			return nil, fmt.Errorf("batch filter function %s: %w", op, err)
+		}
+		if len(results) != len(rows) {
+			return nil, fmt.Errorf("batch filter function %s returned %d results for %d rows", op, len(results), len(rows))
+		}
+		if e.batched == nil {
+			e.batched = make(map[ComparisonOperator][]bool)
+		}
+		e.batched[op] = results
+	}
+	return e, nil
+}
+
+func collectOperators(filter *QueryFilter, operators map[ComparisonOperator]struct{}) {
+	if filter == nil {
+		return
+	}
+	if filter.Condition != nil {
+		operators[filter.Condition.Operator] = struct{}{}
+	}
+	if filter.Group != nil {
+		for i := range filter.Group.Conditions {
+			collectOperators(&filter.Group.Conditions[i], operators)
+		}
+	}
+}
+
+// truth evaluates filter against row, the i-th of the evaluator's rows.
+func (e *rowEvaluator) truth(i int, row Row, filter *QueryFilter) (Truth, error) {
+	if filter == nil {
+		return TruthTrue, nil
+	}
+	if filter.Raw != nil {
+		return TruthFalse, fmt.Errorf("raw conditions cannot be evaluated in Go: %q", filter.Raw.SQL)
+	}
+	if filter.Condition != nil {
+		return e.condition(i, row, filter.Condition)
+	}
+	if filter.Group == nil {
+		return TruthTrue, nil
+	}
+
+	results := make([]Truth, len(filter.Group.Conditions))
+	for j := range filter.Group.Conditions {
+		t, err := e.truth(i, row, &filter.Group.Conditions[j])
+		if err != nil {
+			return TruthFalse, err
+		}
+		results[j] = t
+	}
+
+	switch filter.Group.Operator {
+	case LogicalOperatorAnd:
+		return allOf(results), nil
+	case LogicalOperatorOr:
+		return anyOf(results), nil
+	case LogicalOperatorNot:
+		return allOf(results).Not(), nil
+	case LogicalOperatorNor:
+		return anyOf(results).Not(), nil
+	case LogicalOperatorXor:
+		odd := false
+		for _, t := range results {
+			if t == TruthUnknown {
+				return TruthUnknown, nil
+			}
+			if t == TruthTrue {
+				odd = !odd
+			}
+		}
+		return truthOf(odd), nil
+	default:
+		return TruthFalse, fmt.Errorf("unsupported logical operator: %s", filter.Group.Operator)
+	}
+}
+
+func allOf(results []Truth) Truth {
+	result := TruthTrue
+	for _, t := range results {
+		if t == TruthFalse {
+			return TruthFalse
+		}
+		if t == TruthUnknown {
+			result = TruthUnknown
+		}
+	}
+	return result
+}
+
+func anyOf(results []Truth) Truth {
+	result := TruthFalse
+	for _, t := range results {
+		if t == TruthTrue {
+			return TruthTrue
+		}
+		if t == TruthUnknown {
+			result = TruthUnknown
+		}
+	}
+	return result
+}
+
+func (e *rowEvaluator) condition(i int, row Row, cond *FilterCondition) (Truth, error) {
+	if !cond.Operator.IsStandard() {
+		if results, ok := e.batched[cond.Operator]; ok {
+			return truthOf(results[i]), nil
+		}
+		fn, ok := e.opts.Filters[cond.Operator]
+		if !ok {
+			return TruthFalse, fmt.Errorf("unregistered Go filter function: %s", cond.Operator)
+		}
+		matched, err := fn(row)
+		return truthOf(matched), err
+	}
+
+	fieldValue, err := resolveFieldValue(row, cond)
+	if err != nil {
+		return TruthFalse, err
+	}
+	bound, err := bindBooleans(cond.Value, e.opts.BooleanBinding)
+	if err != nil {
+		return TruthFalse, err
+	}
+	filterValue, err := resolveFilterValue(row, bound)
+	if err != nil {
+		return TruthFalse, err
+	}
+	if cond.Collation != "" {
+		if !cond.Collation.IsValid() {
+			return TruthFalse, fmt.Errorf("unknown collation: %s", cond.Collation)
+		}
+		fieldValue = collate(fieldValue, cond.Collation)
+		filterValue = collateAll(filterValue, cond.Collation)
+	}
+
+	if fieldValue == nil && cond.IncludeNulls {
+		return TruthTrue, nil
+	}
+
+	evaluate, ok := e.opts.Evaluators.lookup(cond.Operator)
+	if !ok {
+		return TruthFalse, fmt.Errorf("unsupported comparison operator in Go evaluation: %s", cond.Operator)
+	}
+	result, err := evaluate(fieldValue, filterValue)
+	if err != nil {
+		return TruthFalse, fmt.Errorf("operator %s on field %s: %w", cond.Operator, cond.Field, err)
+	}
+	return result, nil
+}
+
+// resolveFieldValue reads the condition's field from the row and applies its
+// cast and field expression, mirroring how the SQL left-hand side is built.
+func resolveFieldValue(row Row, cond *FilterCondition) (any, error) {
+	value, err := driverValue(row[cond.Field])
+	if err != nil {
+		return nil, fmt.Errorf("field %s: %w", cond.Field, err)
+	}
+	if cond.Cast != "" {
+		cast, err := castValue(value, cond.Cast)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", cond.Field, err)
+		}
+		value = cast
+	}
+	if expr := cond.Expression; expr != nil && value != nil {
+		if expr.Function == FieldFunctionLength {
+			s, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("field %s: length requires a string value, got %T", cond.Field, value)
+			}
+			value = int64(len([]rune(s)))
+		} else if expr.Function != "" {
+			return nil, fmt.Errorf("field %s: unsupported field function: %s", cond.Field, expr.Function)
+		}
+		if expr.Operator != "" {
+			result, err := applyArithmetic(value, expr.Operator, expr.Operand)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", cond.Field, err)
+			}
+			value = result
+		}
+	}
+	return value, nil
+}
+
+// resolveFilterValue turns special filter values into plain values. Column
+// references are read from the row; database-evaluated values cannot be
+// computed in Go.
+func resolveFilterValue(row Row, value FilterValue) (any, error) {
+	switch v := value.(type) {
+	case ColumnRef:
+		return driverValue(row[v.Name])
+	case *ColumnRef:
+		return driverValue(row[v.Name])
+	case SQLFunc:
+		return nil, fmt.Errorf("SQL function %s cannot be evaluated in Go", v)
+	case Subquery, *Subquery:
+		return nil, fmt.Errorf("subqueries cannot be evaluated in Go")
+	}
+	return driverValue(value)
+}
+
+// Storage classes in SQLite's cross-type ordering: numbers sort before text,
+// which sorts before blobs.
+const (
+	classNumeric = iota
+	classText
+	classBlob
+	classOther
+)
+
+// compareValues orders two non-nil values the way SQLite does: numbers
+// (including booleans) compare numerically, text lexically, blobs bytewise,
+// and values of different storage classes by class. Two integers compare
+// exactly; only a comparison involving a float goes through float64. It
+// returns -1, 0 or 1.
+func compareValues(a, b any) int {
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return ta.Compare(tb)
+		}
+	}
+	ca, cb := storageClass(a), storageClass(b)
+	if ca != cb {
+		if ca < cb {
+			return -1
+		}
+		return 1
+	}
+	switch ca {
+	case classNumeric:
+		if isInteger(a) && isInteger(b) {
+			return compareIntegers(a, b)
+		}
+		fa, _ := toFloat(a)
+		fb, _ := toFloat(b)
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	case classText:
+		return strings.Compare(toText(a), toText(b))
+	case classBlob:
+		return bytes.Compare(a.([]byte), b.([]byte))
+	}
+	if reflect.DeepEqual(a, b) {
+		return 0
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// compareIntegers compares two integer values (see isInteger) without
+// converting them to float64, so values beyond 2^53 keep their precision.
+func compareIntegers(a, b any) int {
+	negA, magA := integerParts(a)
+	negB, magB := integerParts(b)
+	if negA != negB {
+		if negA {
+			return -1
+		}
+		return 1
+	}
+	c := 0
+	switch {
+	case magA < magB:
+		c = -1
+	case magA > magB:
+		c = 1
+	}
+	if negA {
+		return -c
+	}
+	return c
+}
+
+// integerParts splits an integer value into its sign and magnitude.
+func integerParts(v any) (negative bool, magnitude uint64) {
+	var n int64
+	switch i := v.(type) {
+	case int:
+		n = int64(i)
+	case int8:
+		n = int64(i)
+	case int16:
+		n = int64(i)
+	case int32:
+		n = int64(i)
+	case int64:
+		n = i
+	case uint:
+		return false, uint64(i)
+	case uint8:
+		return false, uint64(i)
+	case uint16:
+		return false, uint64(i)
+	case uint32:
+		return false, uint64(i)
+	case uint64:
+		return false, i
+	case bool:
+		if i {
+			return false, 1
+		}
+		return false, 0
+	}
+	if n < 0 {
+		return true, uint64(-(n + 1)) + 1
+	}
+	return false, uint64(n)
+}
+
+// toInt64 converts an integer value (see isInteger) to an int64, reporting
+// false when it does not fit.
+func toInt64(v any) (int64, bool) {
+	negative, magnitude := integerParts(v)
+	if negative {
+		if magnitude > 1<<63 {
+			return 0, false
+		}
+		return int64(-magnitude), true
+	}
+	if magnitude > math.MaxInt64 {
+		return 0, false
+	}
+	return int64(magnitude), true
+}
+
+func storageClass(v any) int {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool:
+		return classNumeric
+	case string, time.Time:
+		return classText
+	case []byte:
+		return classBlob
+	}
+	return classOther
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case bool:
+		if n {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func isInteger(v any) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, bool:
+		return true
+	}
+	return false
+}
+
+func toText(v any) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case []byte:
+		return string(s)
+	case time.Time:
+		return s.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+// toSlice converts an in/nin filter value to a []any.
+func toSlice(value any) ([]any, error) {
+	if values, ok := value.([]any); ok {
+		return values, nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected a list value, got %T", value)
+	}
+	values := make([]any, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values, nil
+}
+
+func collate(value any, collation Collation) any {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+	switch collation {
+	case CollationNoCase:
+		return strings.ToLower(s)
+	case CollationRTrim:
+		return strings.TrimRight(s, " ")
+	}
+	return s
+}
+
+// collateAll applies the collation to a scalar filter value or to each
+// element of a list value.
+func collateAll(value any, collation Collation) any {
+	if _, isBlob := value.([]byte); isBlob {
+		return value
+	}
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		values, _ := toSlice(value)
+		collated := make([]any, len(values))
+		for i, v := range values {
+			collated[i] = collate(v, collation)
+		}
+		return collated
+	}
+	return collate(value, collation)
+}
+
+// bindBooleans applies the boolean binding to a scalar filter value or to
+// each element of a list value. Lists without booleans are returned as-is.
+func bindBooleans(value any, binding BooleanBinding) (any, error) {
+	if _, isBlob := value.([]byte); isBlob {
+		return value, nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return binding.Bind(value)
+	}
+	values, _ := toSlice(value)
+	var bound []any
+	for i, v := range values {
+		if _, ok := v.(bool); !ok {
+			continue
+		}
+		if bound == nil {
+			bound = append([]any(nil), values...)
+		}
+		b, err := binding.Bind(v)
+		if err != nil {
+			return nil, err
+		}
+		bound[i] = b
+	}
+	if bound == nil {
+		return value, nil
+	}
+	return bound, nil
+}
+
+func castValue(value any, cast CastType) (any, error) {
+	if !cast.IsValid() {
+		return nil, fmt.Errorf("unknown cast type: %s", cast)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	switch cast {
+	case CastTypeInteger:
+		if isInteger(value) {
+			if i, ok := toInt64(value); ok {
+				return i, nil
+			}
+			return int64(math.MaxInt64), nil
+		}
+		if f, ok := toFloat(value); ok {
+			return floatToInt64(f), nil
+		}
+		n := numericPrefix(toText(value))
+		if i, ok := n.(int64); ok {
+			return i, nil
+		}
+		return floatToInt64(n.(float64)), nil
+	case CastTypeNumeric:
+		if isInteger(value) {
+			if i, ok := toInt64(value); ok {
+				return i, nil
+			}
+		}
+		if f, ok := toFloat(value); ok {
+			return f, nil
+		}
+		return numericPrefix(toText(value)), nil
+	case CastTypeReal:
+		if f, ok := toFloat(value); ok {
+			return f, nil
+		}
+		f, _ := toFloat(numericPrefix(toText(value)))
+		return f, nil
+	case CastTypeText:
+		return toText(value), nil
+	case CastTypeBlob:
+		return []byte(toText(value)), nil
+	}
+	return value, nil
+}
+
+// numericPrefix converts text to a number the way SQLite's CAST does: it
+// parses the longest numeric prefix after leading whitespace, so "12abc" is
+// 12 and text without one is 0. The result is an int64 when the prefix is an
+// integer literal that fits, and a float64 otherwise.
+func numericPrefix(s string) any {
+	s = strings.TrimLeft(s, " \t\n\r\f\v")
+	i := 0
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	digits := 0
+	for ; i < len(s) && isDigit(s[i]); i++ {
+		digits++
+	}
+	integerEnd := i
+	if i < len(s) && s[i] == '.' {
+		j := i + 1
+		for ; j < len(s) && isDigit(s[j]); j++ {
+			digits++
+		}
+		if digits > 0 {
+			i = j
+		}
+	}
+	if digits == 0 {
+		return int64(0)
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if j < len(s) && (s[j] == '+' || s[j] == '-') {
+			j++
+		}
+		k := j
+		for k < len(s) && isDigit(s[k]) {
+			k++
+		}
+		if k > j {
+			i = k
+		}
+	}
+	if i == integerEnd {
+		if n, err := strconv.ParseInt(s[:i], 10, 64); err == nil {
+			return n
+		}
+	}
+	f, _ := strconv.ParseFloat(s[:i], 64)
+	return f
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// floatToInt64 truncates f toward zero, saturating at the int64 range like
+// SQLite's conversion of REAL to INTEGER.
+func floatToInt64(f float64) int64 {
+	switch {
+	case math.IsNaN(f):
+		return 0
+	case f >= math.MaxInt64:
+		return math.MaxInt64
+	case f <= math.MinInt64:
+		return math.MinInt64
+	}
+	return int64(f)
+}
+
+// applyArithmetic evaluates value op operand like SQLite: integer operands
+// give an INTEGER result, falling back to REAL on overflow, any REAL operand
+// gives a REAL result, and text operands are converted with numericPrefix.
+// Division and modulo by zero are NULL.
+func applyArithmetic(value any, op ArithmeticOperator, operand any) (any, error) {
+	a, ok := arithmeticOperand(value)
+	if !ok {
+		return nil, fmt.Errorf("arithmetic requires a numeric value, got %T", value)
+	}
+	b, ok := arithmeticOperand(operand)
+	if !ok {
+		return nil, fmt.Errorf("arithmetic requires a numeric operand, got %T", operand)
+	}
+	ai, aInt := a.(int64)
+	bi, bInt := b.(int64)
+	if aInt && bInt {
+		if result, ok := integerArithmetic(ai, op, bi); ok {
+			return result, nil
+		}
+	}
+	fa, _ := toFloat(a)
+	fb, _ := toFloat(b)
+	switch op {
+	case ArithmeticOperatorAdd:
+		return fa + fb, nil
+	case ArithmeticOperatorSub:
+		return fa - fb, nil
+	case ArithmeticOperatorMul:
+		return fa * fb, nil
+	case ArithmeticOperatorDiv:
+		if fb == 0 {
+			return nil, nil
+		}
+		return fa / fb, nil
+	case ArithmeticOperatorMod:
+		if math.Trunc(fb) == 0 {
+			return nil, nil
+		}
+		return math.Mod(math.Trunc(fa), math.Trunc(fb)), nil
+	}
+	return nil, fmt.Errorf("unsupported arithmetic operator: %s", op)
+}
+
+// integerArithmetic evaluates a op b in int64, reporting false when the
+// result overflows or op is unknown so the caller can fall back to float64.
+func integerArithmetic(a int64, op ArithmeticOperator, b int64) (any, bool) {
+	switch op {
+	case ArithmeticOperatorAdd:
+		if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+			return nil, false
+		}
+		return a + b, true
+	case ArithmeticOperatorSub:
+		if (b < 0 && a > math.MaxInt64+b) || (b > 0 && a < math.MinInt64+b) {
+			return nil, false
+		}
+		return a - b, true
+	case ArithmeticOperatorMul:
+		r := a * b
+		if a != 0 && (r/a != b || (a == -1 && b == math.MinInt64)) {
+			return nil, false
+		}
+		return r, true
+	case ArithmeticOperatorDiv:
+		if b == 0 {
+			return nil, true
+		}
+		if a == math.MinInt64 && b == -1 {
+			return nil, false
+		}
+		return a / b, true
+	case ArithmeticOperatorMod:
+		if b == 0 {
+			return nil, true
+		}
+		return a % b, true
+	}
+	return nil, false
+}
+
+// arithmeticOperand converts an operand of arithmetic to an int64 or a
+// float64: integers that fit stay integers, reals stay reals and text is
+// converted with numericPrefix, as SQLite does. Other values are rejected.
+func arithmeticOperand(v any) (any, bool) {
+	if isInteger(v) {
+		if i, ok := toInt64(v); ok {
+			return i, true
+		}
+	}
+	if f, ok := toFloat(v); ok {
+		return f, true
+	}
+	switch s := v.(type) {
+	case string:
+		return numericPrefix(s), true
+	case []byte:
+		return numericPrefix(string(s)), true
+	}
+	return nil, false
+}
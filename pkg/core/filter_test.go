@@ -0,0 +1,485 @@
+package core
+
+import (
+	"database/sql"
+	"math"
+	"testing"
+)
+
+// filterTestRows mirrors a table with a nullable age column; the expected
+// ids in TestFilterRowsMatchesSQL are what SQLite returns for the SQL in
+// each case's comment.
+var filterTestRows = []Row{
+	{"id": int64(1), "name": "alice", "age": int64(25)},
+	{"id": int64(2), "name": "Bob", "age": int64(30)},
+	{"id": int64(3), "name": "carol", "age": nil},
+	{"id": int64(4), "name": nil, "age": int64(40)},
+}
+
+func ids(rows []Row) []int64 {
+	out := make([]int64, len(rows))
+	for i, row := range rows {
+		out[i] = row["id"].(int64)
+	}
+	return out
+}
+
+func equalIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFilterRowsMatchesSQL(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter QueryFilter
+		want   []int64
+	}{
+		// age = 25
+		{"eq", cond("age", ComparisonOperatorEq, 25), []int64{1}},
+		// age <> 25
+		{"neq skips null", cond("age", ComparisonOperatorNeq, 25), []int64{2, 4}},
+		// age > 26
+		{"gt", cond("age", ComparisonOperatorGt, 26), []int64{2, 4}},
+		// age IN (25, NULL)
+		{"in with null", cond("age", ComparisonOperatorIn, []any{25, nil}), []int64{1}},
+		// age NOT IN (25, NULL)
+		{"nin with null", cond("age", ComparisonOperatorNin, []any{25, nil}), nil},
+		// age NOT IN (25)
+		{"nin", cond("age", ComparisonOperatorNin, []any{25}), []int64{2, 4}},
+		// age IN ()
+		{"in empty", cond("age", ComparisonOperatorIn, []any{}), nil},
+		// age NOT IN ()
+		{"nin empty", cond("age", ComparisonOperatorNin, []any{}), []int64{1, 2, 3, 4}},
+		// name LIKE '%b%'
+		{"contains ignores case", cond("name", ComparisonOperatorContains, "b"), []int64{2}},
+		// name NOT LIKE '%b%'
+		{"ncontains skips null", cond("name", ComparisonOperatorNotContains, "b"), []int64{1, 3}},
+		// age IS NULL
+		{"nexists", cond("age", ComparisonOperatorNotExists, nil), []int64{3}},
+		// age IS NOT 25
+		{"isnot", cond("age", ComparisonOperatorIsNot, 25), []int64{2, 3, 4}},
+		// NOT (age = 25)
+		{"not excludes null", group(LogicalOperatorNot, cond("age", ComparisonOperatorEq, 25)), []int64{2, 4}},
+		// NOT (age = 25 AND name = 'alice')
+		{"not of and", group(LogicalOperatorNot,
+			cond("age", ComparisonOperatorEq, 25),
+			cond("name", ComparisonOperatorEq, "alice"),
+		), []int64{2, 3, 4}},
+		// age = 25 OR name = 'carol'
+		{"or with unknown", group(LogicalOperatorOr,
+			cond("age", ComparisonOperatorEq, 25),
+			cond("name", ComparisonOperatorEq, "carol"),
+		), []int64{1, 3}},
+		// NOT (age > 26 OR name = 'alice')
+		{"nor", group(LogicalOperatorNor,
+			cond("age", ComparisonOperatorGt, 26),
+			cond("name", ComparisonOperatorEq, "alice"),
+		), nil},
+		// age < 35 AND name IS NOT NULL
+		{"and", group(LogicalOperatorAnd,
+			cond("age", ComparisonOperatorLt, 35),
+			cond("name", ComparisonOperatorExists, nil),
+		), []int64{1, 2}},
+		// (age > 26) XOR (name = 'Bob')
+		{"xor", group(LogicalOperatorXor,
+			cond("age", ComparisonOperatorGt, 26),
+			cond("name", ComparisonOperatorEq, "Bob"),
+		), nil},
+		// NOT (NOT (age < 35))
+		{"double not", group(LogicalOperatorNot,
+			group(LogicalOperatorNot, cond("age", ComparisonOperatorLt, 35)),
+		), []int64{1, 2}},
+		// age <> 25 OR age IS NULL
+		{"include nulls", QueryFilter{Condition: &FilterCondition{
+			Field: "age", Operator: ComparisonOperatorNeq, Value: 25, IncludeNulls: true,
+		}}, []int64{2, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FilterRows(filterTestRows, &tt.filter, EvalOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !equalIDs(ids(got), tt.want) {
+				t.Errorf("got ids %v, want %v", ids(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterRowsMixedFilters(t *testing.T) {
+	customFns := map[ComparisonOperator]GoFilterFunction{
+		"short_name": func(row Row) (bool, error) {
+			name, _ := row["name"].(string)
+			return len(name) <= 3, nil
+		},
+	}
+	// (age > 26 OR short_name) AND NOT (age = 40)
+	filter := group(LogicalOperatorAnd,
+		group(LogicalOperatorOr,
+			cond("age", ComparisonOperatorGt, 26),
+			cond("name", "short_name", nil),
+		),
+		group(LogicalOperatorNot, cond("age", ComparisonOperatorEq, 40)),
+	)
+	got, err := FilterRows(filterTestRows, &filter, EvalOptions{Filters: customFns})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{2}; !equalIDs(ids(got), want) {
+		t.Errorf("got ids %v, want %v", ids(got), want)
+	}
+
+	if _, err := FilterRows(filterTestRows, &QueryFilter{Condition: &FilterCondition{Field: "age", Operator: "missing"}}, EvalOptions{Filters: customFns}); err == nil {
+		t.Error("expected an error for an unregistered operator")
+	}
+}
+
+func TestEvaluateFilterTruth(t *testing.T) {
+	row := Row{"a": nil, "b": int64(1)}
+	unknown := cond("a", ComparisonOperatorEq, 1)
+	isTrue := cond("b", ComparisonOperatorEq, 1)
+	isFalse := cond("b", ComparisonOperatorEq, 2)
+	tests := []struct {
+		name   string
+		filter QueryFilter
+		want   Truth
+	}{
+		{"null comparison", unknown, TruthUnknown},
+		{"not unknown", group(LogicalOperatorNot, unknown), TruthUnknown},
+		{"unknown and false", group(LogicalOperatorAnd, unknown, isFalse), TruthFalse},
+		{"unknown and true", group(LogicalOperatorAnd, unknown, isTrue), TruthUnknown},
+		{"unknown or true", group(LogicalOperatorOr, unknown, isTrue), TruthTrue},
+		{"unknown or false", group(LogicalOperatorOr, unknown, isFalse), TruthUnknown},
+		{"nor unknown false", group(LogicalOperatorNor, unknown, isFalse), TruthUnknown},
+		{"xor unknown", group(LogicalOperatorXor, unknown, isTrue), TruthUnknown},
+		{"xor", group(LogicalOperatorXor, isTrue, isFalse), TruthTrue},
+		{"empty group", group(LogicalOperatorAnd), TruthTrue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EvaluateFilterTruth(row, &tt.filter, EvalOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateFilterApproxEq(t *testing.T) {
+	tests := []struct {
+		value any
+		pair  any
+		want  Truth
+	}{
+		{0.1 + 0.2, []any{0.3, 1e-9}, TruthTrue},
+		{int64(10), []float64{9, 0.5}, TruthFalse},
+		{"10", []any{10, 1}, TruthTrue},
+		{"10.4kg", []any{10, 0.5}, TruthTrue},
+		{"abc", []any{10, 1}, TruthFalse},
+		{"abc", []any{0, 0}, TruthTrue},
+		{nil, []any{10, 1}, TruthUnknown},
+	}
+	for _, tt := range tests {
+		filter := cond("v", ComparisonOperatorApproxEq, tt.pair)
+		got, err := EvaluateFilterTruth(Row{"v": tt.value}, &filter, EvalOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%#v approxeq %v = %v, want %v", tt.value, tt.pair, got, tt.want)
+		}
+	}
+	for _, pair := range []any{[]any{1.0}, []any{"a", 1}} {
+		filter := cond("v", ComparisonOperatorApproxEq, pair)
+		if _, err := EvaluateFilterTruth(Row{"v": 1.0}, &filter, EvalOptions{}); err == nil {
+			t.Errorf("approxeq %v: expected an error", pair)
+		}
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b any
+		want int
+	}{
+		{"int64 beyond float precision", int64(math.MaxInt64), int64(math.MaxInt64 - 1), 1},
+		{"uint64 above int64", uint64(math.MaxUint64), int64(math.MaxInt64), 1},
+		{"negative vs uint", int64(-1), uint64(0), -1},
+		{"min int64", int64(math.MinInt64), int64(math.MinInt64 + 1), -1},
+		{"mixed integer types", int32(7), uint8(7), 0},
+		{"int vs float", int64(2), 2.5, -1},
+		{"bool as integer", true, int64(1), 0},
+		{"number before text", int64(10), "1", -1},
+		{"text before blob", "z", []byte("a"), -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareValues(tt.a, tt.b); got != tt.want {
+				t.Errorf("compareValues(%v, %v) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterRowsBatchFilters(t *testing.T) {
+	calls := 0
+	opts := EvalOptions{
+		Filters: map[ComparisonOperator]GoFilterFunction{
+			"vip": func(Row) (bool, error) { return false, nil },
+		},
+		BatchFilters: map[ComparisonOperator]GoBatchFilterFunction{
+			"vip": func(rows []Row) ([]bool, error) {
+				calls++
+				results := make([]bool, len(rows))
+				for i, row := range rows {
+					results[i] = row["id"].(int64)%2 == 0
+				}
+				return results, nil
+			},
+		},
+	}
+	// vip OR (age = 25 AND vip)
+	filter := group(LogicalOperatorOr,
+		cond("id", "vip", nil),
+		group(LogicalOperatorAnd, cond("age", ComparisonOperatorEq, 25), cond("id", "vip", nil)),
+	)
+	got, err := FilterRows(filterTestRows, &filter, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{2, 4}; !equalIDs(ids(got), want) {
+		t.Errorf("got ids %v, want %v", ids(got), want)
+	}
+	if calls != 1 {
+		t.Errorf("batch function called %d times, want 1", calls)
+	}
+
+	opts.BatchFilters["vip"] = func(rows []Row) ([]bool, error) { return []bool{true}, nil }
+	if _, err := FilterRows(filterTestRows, &filter, opts); err == nil {
+		t.Error("expected an error for a batch result of the wrong length")
+	}
+}
+
+func TestFilterRowsUnwrapsValuers(t *testing.T) {
+	rows := []Row{
+		{"id": int64(1), "age": sql.NullInt64{Int64: 25, Valid: true}, "name": &sql.NullString{String: "alice", Valid: true}},
+		{"id": int64(2), "age": sql.NullInt64{}, "name": &sql.NullString{}},
+		{"id": int64(3), "age": sql.NullInt64{Int64: 30, Valid: true}, "name": (*sql.NullString)(nil)},
+	}
+	tests := []struct {
+		name   string
+		filter QueryFilter
+		want   []int64
+	}{
+		{"eq", cond("age", ComparisonOperatorEq, 25), []int64{1}},
+		{"null valuer is unknown", cond("age", ComparisonOperatorNeq, 25), []int64{3}},
+		{"nexists", cond("name", ComparisonOperatorNotExists, nil), []int64{2, 3}},
+		{"column ref", cond("age", ComparisonOperatorLt, ColumnRef{Name: "id"}), nil},
+		{"valuer filter value", cond("age", ComparisonOperatorEq, sql.NullInt64{Int64: 30, Valid: true}), []int64{3}},
+	}
+	for _, tt := range tests {
+		got, err := FilterRows(rows, &tt.filter, EvalOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equalIDs(ids(got), tt.want) {
+			t.Errorf("%s: got ids %v, want %v", tt.name, ids(got), tt.want)
+		}
+	}
+
+	if err := SortRows(rows, []SortConfiguration{{Field: "age", Direction: SortDirectionDesc}}, EvalOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{3, 1, 2}; !equalIDs(ids(rows), want) {
+		t.Errorf("sorted by valuer: got ids %v, want %v", ids(rows), want)
+	}
+	if best := ExtremeRow(rows, "age", false); best == nil || best["id"] != int64(1) {
+		t.Errorf("ExtremeRow min: got %v", best)
+	}
+}
+
+func TestCastValue(t *testing.T) {
+	tests := []struct {
+		value any
+		cast  CastType
+		want  any
+	}{
+		{int64(9007199254740993), CastTypeInteger, int64(9007199254740993)},
+		{"9007199254740993", CastTypeInteger, int64(9007199254740993)},
+		{uint64(math.MaxUint64), CastTypeInteger, int64(math.MaxInt64)},
+		{12.9, CastTypeInteger, int64(12)},
+		{-12.9, CastTypeInteger, int64(-12)},
+		{1e300, CastTypeInteger, int64(math.MaxInt64)},
+		{"12abc", CastTypeInteger, int64(12)},
+		{"  -7.5kg", CastTypeInteger, int64(-7)},
+		{"1e3", CastTypeInteger, int64(1000)},
+		{"abc", CastTypeInteger, int64(0)},
+		{"99999999999999999999", CastTypeInteger, int64(math.MaxInt64)},
+		{true, CastTypeInteger, int64(1)},
+		{"12.5abc", CastTypeReal, 12.5},
+		{".5", CastTypeReal, 0.5},
+		{"-", CastTypeReal, 0.0},
+		{int64(3), CastTypeReal, 3.0},
+		{int64(9007199254740993), CastTypeNumeric, int64(9007199254740993)},
+		{"42", CastTypeNumeric, int64(42)},
+		{"4.2e1x", CastTypeNumeric, 42.0},
+		{int64(42), CastTypeText, "42"},
+		{nil, CastTypeInteger, nil},
+	}
+	for _, tt := range tests {
+		got, err := castValue(tt.value, tt.cast)
+		if err != nil {
+			t.Errorf("CAST(%#v AS %s): %v", tt.value, tt.cast, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("CAST(%#v AS %s) = %#v, want %#v", tt.value, tt.cast, got, tt.want)
+		}
+	}
+	if _, err := castValue(1, "DATE"); err == nil {
+		t.Error("unknown cast type: expected an error")
+	}
+}
+
+func TestFilterRowsFieldExpression(t *testing.T) {
+	rows := []Row{
+		{"id": int64(1)},
+		{"id": int64(2)},
+		{"id": int64(9007199254740993)},
+		{"id": int64(9007199254740994)},
+		{"id": nil},
+	}
+	// "id" % 2 = 0
+	even := QueryFilter{Condition: &FilterCondition{
+		Field: "id", Operator: ComparisonOperatorEq, Value: 0,
+		Expression: &FieldExpression{Operator: ArithmeticOperatorMod, Operand: 2},
+	}}
+	got, err := FilterRows(rows, &even, EvalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{2, 9007199254740994}; !equalIDs(ids(got), want) {
+		t.Errorf("id %% 2 = 0: got ids %v, want %v", ids(got), want)
+	}
+}
+
+func TestApplyArithmetic(t *testing.T) {
+	tests := []struct {
+		value   any
+		op      ArithmeticOperator
+		operand any
+		want    any
+	}{
+		{int64(9007199254740993), ArithmeticOperatorMod, 2, int64(1)},
+		{int64(9007199254740993), ArithmeticOperatorAdd, 1, int64(9007199254740994)},
+		{int64(7), ArithmeticOperatorSub, int32(10), int64(-3)},
+		{int64(6), ArithmeticOperatorMul, 7, int64(42)},
+		{int64(7), ArithmeticOperatorDiv, 2, int64(3)},
+		{int64(-7), ArithmeticOperatorDiv, 2, int64(-3)},
+		{int64(-7), ArithmeticOperatorMod, 3, int64(-1)},
+		{int64(7), ArithmeticOperatorDiv, 2.0, 3.5},
+		{1.5, ArithmeticOperatorAdd, 1, 2.5},
+		{5.5, ArithmeticOperatorMod, 2, 1.0},
+		{int64(math.MaxInt64), ArithmeticOperatorAdd, 1, float64(math.MaxInt64) + 1},
+		{int64(math.MinInt64), ArithmeticOperatorMul, -1, -float64(math.MinInt64)},
+		{int64(math.MinInt64), ArithmeticOperatorDiv, -1, -float64(math.MinInt64)},
+		{"10", ArithmeticOperatorSub, 10, int64(0)},
+		{"2.5", ArithmeticOperatorMul, 2, 5.0},
+		{int64(7), ArithmeticOperatorDiv, 0, nil},
+		{int64(7), ArithmeticOperatorMod, 0, nil},
+		{7.0, ArithmeticOperatorMod, 0.5, nil},
+	}
+	for _, tt := range tests {
+		got, err := applyArithmetic(tt.value, tt.op, tt.operand)
+		if err != nil {
+			t.Errorf("%v %s %v: %v", tt.value, tt.op, tt.operand, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%v %s %v = %#v, want %#v", tt.value, tt.op, tt.operand, got, tt.want)
+		}
+	}
+	if _, err := applyArithmetic(true, "^", 2); err == nil {
+		t.Error("unknown operator: expected an error")
+	}
+	if _, err := applyArithmetic(struct{}{}, ArithmeticOperatorAdd, 1); err == nil {
+		t.Error("non-numeric value: expected an error")
+	}
+}
+
+func TestFilterRowsLength(t *testing.T) {
+	rows := []Row{
+		{"id": int64(1), "first_name": "Al", "age": int64(30)},
+		{"id": int64(2), "first_name": "Bartholomew", "age": int64(41)},
+		{"id": int64(3), "first_name": "Zoë", "age": int64(25)},
+		{"id": int64(4), "first_name": nil, "age": int64(52)},
+	}
+	// LENGTH("first_name") > 2
+	long := QueryFilter{Condition: &FilterCondition{
+		Field: "first_name", Operator: ComparisonOperatorGt, Value: 2,
+		Expression: &FieldExpression{Function: FieldFunctionLength},
+	}}
+	got, err := FilterRows(rows, &long, EvalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{2, 3}; !equalIDs(ids(got), want) {
+		t.Errorf("got ids %v, want %v", ids(got), want)
+	}
+
+	onAge := QueryFilter{Condition: &FilterCondition{
+		Field: "age", Operator: ComparisonOperatorGt, Value: 1,
+		Expression: &FieldExpression{Function: FieldFunctionLength},
+	}}
+	if _, err := FilterRows(rows, &onAge, EvalOptions{}); err == nil {
+		t.Error("length of a non-string column: expected an error")
+	}
+}
+
+func TestFilterRowsBooleanBinding(t *testing.T) {
+	rows := []Row{
+		{"id": int64(1), "is_active": "true"},
+		{"id": int64(2), "is_active": "false"},
+		{"id": int64(3), "is_active": nil},
+	}
+	active := cond("is_active", ComparisonOperatorEq, true)
+	got, err := FilterRows(rows, &active, EvalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("int binding: got ids %v, want none", ids(got))
+	}
+
+	opts := EvalOptions{BooleanBinding: BooleanBindingString}
+	if got, err = FilterRows(rows, &active, opts); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{1}; !equalIDs(ids(got), want) {
+		t.Errorf("string binding: got ids %v, want %v", ids(got), want)
+	}
+	inactive := cond("is_active", ComparisonOperatorIn, []bool{false})
+	if got, err = FilterRows(rows, &inactive, opts); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{2}; !equalIDs(ids(got), want) {
+		t.Errorf("string binding of a list: got ids %v, want %v", ids(got), want)
+	}
+
+	if _, err := FilterRows(rows, &active, EvalOptions{BooleanBinding: "yes_no"}); err == nil {
+		t.Error("unknown boolean binding: expected an error")
+	}
+}
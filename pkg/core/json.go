@@ -0,0 +1,222 @@
+package core
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// JSONOptions controls how query results are serialized by ResultToJSON and RowToJSON.
+type JSONOptions struct {
+	// Columns is the column order to use for row objects, typically the
+	// projection order. Columns missing from a row are skipped; row fields not
+	// listed are written afterwards in alphabetical order.
+	Columns []string
+	// BytesAsString writes []byte values as strings instead of base64.
+	BytesAsString bool
+}
+
+// ResultToJSON serializes a QueryResult to JSON, normalizing Row values that
+// encoding/json would misrepresent or reject: []byte is written as base64 (or
+// a string, see JSONOptions), time.Time as RFC 3339, NaN or infinite floats as
+// null, and driver.Valuer values such as sql.NullString (e.g. scanners from a
+// ScannerFactory) as the value they report. Row objects keep the column order
+// given in opts.
+func ResultToJSON(result *QueryResult, opts JSONOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(`{"data":`)
+	if err := writeJSONValue(&buf, result.Data, opts); err != nil {
+		return nil, err
+	}
+	if result.Pagination != nil {
+		if err := writeJSONField(&buf, "Pagination", result.Pagination, opts); err != nil {
+			return nil, err
+		}
+	}
+	if len(result.Aggregations) > 0 {
+		if err := writeJSONField(&buf, "Aggregations", result.Aggregations, opts); err != nil {
+			return nil, err
+		}
+	}
+	if len(result.Window) > 0 {
+		if err := writeJSONField(&buf, "Window", result.Window, opts); err != nil {
+			return nil, err
+		}
+	}
+	if result.Timing != nil {
+		if err := writeJSONField(&buf, "Timing", result.Timing, opts); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// RowToJSON serializes a single Row to a JSON object using the same value
+// normalization and column ordering as ResultToJSON.
+func RowToJSON(row Row, opts JSONOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeJSONObject(&buf, row, opts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeJSONField(buf *bytes.Buffer, name string, value any, opts JSONOptions) error {
+	buf.WriteString(`,"` + name + `":`)
+	return writeJSONValue(buf, value, opts)
+}
+
+func writeJSONValue(buf *bytes.Buffer, value any, opts JSONOptions) error {
+	value, err := driverValue(value)
+	if err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case Row:
+		return writeJSONObject(buf, v, opts)
+	case map[string]any:
+		return writeJSONObject(buf, v, opts)
+	case []Row:
+		buf.WriteByte('[')
+		for i, row := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSONObject(buf, row, opts); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case []map[string]any:
+		buf.WriteByte('[')
+		for i, row := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSONObject(buf, row, opts); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSONValue(buf, item, opts); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	}
+
+	encoded, err := json.Marshal(normalizeJSONScalar(value, opts))
+	if err != nil {
+		return err
+	}
+	buf.Write(encoded)
+	return nil
+}
+
+func writeJSONObject(buf *bytes.Buffer, obj map[string]any, opts JSONOptions) error {
+	buf.WriteByte('{')
+	first := true
+	for _, key := range orderedKeys(obj, opts.Columns) {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		encodedKey, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+		if err := writeJSONValue(buf, obj[key], opts); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// orderedKeys returns the keys of obj, listing those in columns first (in
+// that order) followed by the remaining keys sorted alphabetically.
+func orderedKeys(obj map[string]any, columns []string) []string {
+	keys := make([]string, 0, len(obj))
+	seen := make(map[string]struct{}, len(columns))
+	for _, col := range columns {
+		if _, ok := obj[col]; !ok {
+			continue
+		}
+		if _, dup := seen[col]; dup {
+			continue
+		}
+		seen[col] = struct{}{}
+		keys = append(keys, col)
+	}
+	rest := make([]string, 0, len(obj)-len(keys))
+	for key := range obj {
+		if _, ok := seen[key]; !ok {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+func normalizeJSONScalar(value any, opts JSONOptions) any {
+	switch v := value.(type) {
+	case []byte:
+		if opts.BytesAsString {
+			return string(v)
+		}
+		return base64.StdEncoding.EncodeToString(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return v.Format(time.RFC3339)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil
+		}
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return nil
+		}
+	}
+	return value
+}
+
+// driverValue unwraps a driver.Valuer, such as sql.NullInt64 or a scanner
+// stored by a ScannerFactory, to the plain value it reports, so that it
+// compares and serializes like the database value it holds. A nil pointer
+// Valuer is NULL. Other values are returned unchanged.
+func driverValue(value any) (any, error) {
+	valuer, ok := value.(driver.Valuer)
+	if !ok {
+		return value, nil
+	}
+	if rv := reflect.ValueOf(valuer); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, nil
+	}
+	v, err := valuer.Value()
+	if err != nil {
+		return nil, fmt.Errorf("reading %T value: %w", value, err)
+	}
+	return v, nil
+}
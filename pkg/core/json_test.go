@@ -0,0 +1,78 @@
+package core
+
+import (
+	"database/sql"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRowToJSON(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	var nilTime *time.Time
+	var nilScanner *sql.NullString
+	tests := []struct {
+		name string
+		row  Row
+		opts JSONOptions
+		want string
+	}{
+		{"bytes as base64", Row{"b": []byte("hi")}, JSONOptions{}, `{"b":"aGk="}`},
+		{"bytes as string", Row{"b": []byte("hi")}, JSONOptions{BytesAsString: true}, `{"b":"hi"}`},
+		{"time", Row{"t": ts}, JSONOptions{}, `{"t":"2024-05-01T12:30:00Z"}`},
+		{"time pointer", Row{"t": &ts, "n": nilTime}, JSONOptions{}, `{"n":null,"t":"2024-05-01T12:30:00Z"}`},
+		{"nan and inf", Row{"a": math.NaN(), "b": math.Inf(1), "c": float32(math.Inf(-1)), "d": 1.5}, JSONOptions{}, `{"a":null,"b":null,"c":null,"d":1.5}`},
+		{"column order", Row{"a": 1, "b": 2, "c": 3, "d": 4}, JSONOptions{Columns: []string{"c", "missing", "a", "c"}}, `{"c":3,"a":1,"b":2,"d":4}`},
+		{"valuers", Row{
+			"s":  sql.NullString{String: "x", Valid: true},
+			"ns": sql.NullString{},
+			"i":  &sql.NullInt64{Int64: 9007199254740993, Valid: true},
+			"p":  nilScanner,
+			"t":  sql.NullTime{Time: ts, Valid: true},
+		}, JSONOptions{}, `{"i":9007199254740993,"ns":null,"p":null,"s":"x","t":"2024-05-01T12:30:00Z"}`},
+		{"nested rows", Row{"order": map[string]any{"total": 2.5, "raw": []byte{1}}}, JSONOptions{}, `{"order":{"raw":"AQ==","total":2.5}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RowToJSON(tt.row, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultToJSON(t *testing.T) {
+	result := &QueryResult{Data: []Row{{"id": int64(1), "name": "a"}, {"id": int64(2), "name": nil}}}
+	got, err := ResultToJSON(result, JSONOptions{Columns: []string{"name", "id"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"data":[{"name":"a","id":1},{"name":null,"id":2}]}`; string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestResultToJSONPagination(t *testing.T) {
+	total := 10
+	tests := []struct {
+		pagination *PaginationResult
+		want       string
+	}{
+		{&PaginationResult{Total: &total}, `{"data":[],"Pagination":{"Total":10}}`},
+		{&PaginationResult{HasMore: true}, `{"data":[],"Pagination":{"HasMore":true}}`},
+		{&PaginationResult{Total: &total, Estimated: true}, `{"data":[],"Pagination":{"Total":10,"Estimated":true}}`},
+	}
+	for _, tt := range tests {
+		got, err := ResultToJSON(&QueryResult{Data: []Row{}, Pagination: tt.pagination}, JSONOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("got %s, want %s", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package core
+
+import "reflect"
+
+// BindParams returns a copy of the QueryDSL in which every Param value is
+// replaced by its entry in params. Params are bound wherever the QueryDSL
+// carries values: in conditions of the query's Filters, join conditions and
+// aggregation filters, in field-expression operands and raw-condition
+// parameters, in CASE expressions of Sort, Window ordering and projections,
+// in coalesce defaults and compute-function arguments, inside list values of
+// any slice type, and recursively in Subquery values. It returns
+// ErrUnboundParam for a Param missing from params. The input QueryDSL is not
+// modified.
+func BindParams(dsl *QueryDSL, params map[string]any) (*QueryDSL, error) {
+	if dsl == nil {
+		return nil, nil
+	}
+	return paramBinder(params).dsl(dsl)
+}
+
+// paramBinder copies the parts of a QueryDSL that carry values, binding the
+// Params it finds.
+type paramBinder map[string]any
+
+func (b paramBinder) dsl(dsl *QueryDSL) (*QueryDSL, error) {
+	bound := *dsl
+	var err error
+	if bound.Filters, err = b.filter(dsl.Filters); err != nil {
+		return nil, err
+	}
+	if bound.Sort, err = b.sort(dsl.Sort); err != nil {
+		return nil, err
+	}
+	if bound.Projection, err = b.projection(dsl.Projection); err != nil {
+		return nil, err
+	}
+	if dsl.Joins != nil {
+		bound.Joins = make([]JoinConfiguration, len(dsl.Joins))
+		for i, join := range dsl.Joins {
+			on, err := b.filter(&join.On)
+			if err != nil {
+				return nil, err
+			}
+			join.On = *on
+			if join.Projection, err = b.projection(join.Projection); err != nil {
+				return nil, err
+			}
+			bound.Joins[i] = join
+		}
+	}
+	if dsl.Aggregations != nil {
+		bound.Aggregations = make([]AggregationConfiguration, len(dsl.Aggregations))
+		for i, agg := range dsl.Aggregations {
+			if agg.Filter, err = b.filter(agg.Filter); err != nil {
+				return nil, err
+			}
+			bound.Aggregations[i] = agg
+		}
+	}
+	if dsl.Window != nil {
+		bound.Window = make([]WindowFunction, len(dsl.Window))
+		for i, w := range dsl.Window {
+			if w.Arguments, err = b.arguments(w.Arguments); err != nil {
+				return nil, err
+			}
+			if w.OrderBy, err = b.sort(w.OrderBy); err != nil {
+				return nil, err
+			}
+			bound.Window[i] = w
+		}
+	}
+	return &bound, nil
+}
+
+func (b paramBinder) filter(filter *QueryFilter) (*QueryFilter, error) {
+	if filter == nil {
+		return nil, nil
+	}
+	bound := *filter
+	if filter.Condition != nil {
+		cond := *filter.Condition
+		value, err := b.value(cond.Value)
+		if err != nil {
+			return nil, err
+		}
+		cond.Value = value
+		if cond.Expression != nil {
+			expr := *cond.Expression
+			if expr.Operand, err = b.value(expr.Operand); err != nil {
+				return nil, err
+			}
+			cond.Expression = &expr
+		}
+		bound.Condition = &cond
+	}
+	if filter.Group != nil {
+		conditions := make([]QueryFilter, len(filter.Group.Conditions))
+		for i := range filter.Group.Conditions {
+			child, err := b.filter(&filter.Group.Conditions[i])
+			if err != nil {
+				return nil, err
+			}
+			conditions[i] = *child
+		}
+		bound.Group = &FilterGroup{Operator: filter.Group.Operator, Conditions: conditions}
+	}
+	if filter.Raw != nil {
+		raw := *filter.Raw
+		params, err := b.values(raw.Params)
+		if err != nil {
+			return nil, err
+		}
+		raw.Params = params
+		bound.Raw = &raw
+	}
+	return &bound, nil
+}
+
+func (b paramBinder) value(value any) (any, error) {
+	switch v := value.(type) {
+	case Param:
+		bound, ok := b[string(v)]
+		if !ok {
+			return nil, ErrUnboundParam{Name: string(v)}
+		}
+		return bound, nil
+	case Subquery:
+		dsl, err := BindParams(v.DSL, b)
+		if err != nil {
+			return nil, err
+		}
+		v.DSL = dsl
+		return v, nil
+	case *Subquery:
+		if v == nil {
+			return value, nil
+		}
+		dsl, err := BindParams(v.DSL, b)
+		if err != nil {
+			return nil, err
+		}
+		sub := *v
+		sub.DSL = dsl
+		return &sub, nil
+	case []byte:
+		return value, nil
+	}
+	if rv := reflect.ValueOf(value); rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return value, nil
+	}
+	items, _ := toSlice(value)
+	bound, err := b.values(items)
+	if err != nil {
+		return nil, err
+	}
+	if reflect.DeepEqual(bound, items) {
+		// Keep lists without Params, such as []string, in their original type.
+		return value, nil
+	}
+	return bound, nil
+}
+
+func (b paramBinder) values(values []any) ([]any, error) {
+	if values == nil {
+		return nil, nil
+	}
+	bound := make([]any, len(values))
+	for i, v := range values {
+		item, err := b.value(v)
+		if err != nil {
+			return nil, err
+		}
+		bound[i] = item
+	}
+	return bound, nil
+}
+
+func (b paramBinder) arguments(args []FilterValue) ([]FilterValue, error) {
+	if args == nil {
+		return nil, nil
+	}
+	bound := make([]FilterValue, len(args))
+	for i, arg := range args {
+		item, err := b.value(arg)
+		if err != nil {
+			return nil, err
+		}
+		bound[i] = item
+	}
+	return bound, nil
+}
+
+func (b paramBinder) caseExpression(c *CaseExpression) (*CaseExpression, error) {
+	if c == nil {
+		return nil, nil
+	}
+	bound := *c
+	bound.Cases = make([]CaseCondition, len(c.Cases))
+	for i, when := range c.Cases {
+		filter, err := b.filter(&when.When)
+		if err != nil {
+			return nil, err
+		}
+		then, err := b.value(when.Then)
+		if err != nil {
+			return nil, err
+		}
+		bound.Cases[i] = CaseCondition{When: *filter, Then: then}
+	}
+	var err error
+	if bound.Else, err = b.value(c.Else); err != nil {
+		return nil, err
+	}
+	return &bound, nil
+}
+
+func (b paramBinder) sort(sort []SortConfiguration) ([]SortConfiguration, error) {
+	if sort == nil {
+		return nil, nil
+	}
+	bound := make([]SortConfiguration, len(sort))
+	for i, s := range sort {
+		var err error
+		if s.Case, err = b.caseExpression(s.Case); err != nil {
+			return nil, err
+		}
+		bound[i] = s
+	}
+	return bound, nil
+}
+
+func (b paramBinder) projection(p *ProjectionConfiguration) (*ProjectionConfiguration, error) {
+	if p == nil {
+		return nil, nil
+	}
+	bound := *p
+	var err error
+	if bound.Include, err = b.projectionFields(p.Include); err != nil {
+		return nil, err
+	}
+	if bound.Exclude, err = b.projectionFields(p.Exclude); err != nil {
+		return nil, err
+	}
+	if p.Computed != nil {
+		bound.Computed = make([]ProjectionComputedItem, len(p.Computed))
+		for i, item := range p.Computed {
+			if item.CaseExpression, err = b.caseExpression(item.CaseExpression); err != nil {
+				return nil, err
+			}
+			if c := item.CoalesceExpression; c != nil {
+				coalesce := *c
+				if coalesce.Default, err = b.value(c.Default); err != nil {
+					return nil, err
+				}
+				item.CoalesceExpression = &coalesce
+			}
+			if c := item.ComputedFieldExpression; c != nil && c.Expression != nil {
+				computed, call := *c, *c.Expression
+				if call.Arguments, err = b.arguments(call.Arguments); err != nil {
+					return nil, err
+				}
+				computed.Expression = &call
+				item.ComputedFieldExpression = &computed
+			}
+			bound.Computed[i] = item
+		}
+	}
+	return &bound, nil
+}
+
+func (b paramBinder) projectionFields(fields []ProjectionField) ([]ProjectionField, error) {
+	if fields == nil {
+		return nil, nil
+	}
+	bound := make([]ProjectionField, len(fields))
+	for i, field := range fields {
+		var err error
+		if field.Nested, err = b.projection(field.Nested); err != nil {
+			return nil, err
+		}
+		bound[i] = field
+	}
+	return bound, nil
+}
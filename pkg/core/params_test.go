@@ -0,0 +1,115 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func paramDSL() *QueryDSL {
+	at := func(field string) QueryFilter { return cond(field, ComparisonOperatorEq, Param(field)) }
+	caseOn := func(field string) *CaseExpression {
+		return &CaseExpression{Cases: []CaseCondition{{When: at(field), Then: Param(field + "_then")}}, Else: Param(field + "_else")}
+	}
+	filters := group(LogicalOperatorAnd,
+		at("filters"),
+		cond("status", ComparisonOperatorIn, []FilterValue{"new", Param("status")}),
+		cond("tags", ComparisonOperatorIn, []string{"a", "b"}),
+		QueryFilter{Condition: &FilterCondition{Field: "id", Operator: ComparisonOperatorEq, Value: 0,
+			Expression: &FieldExpression{Operator: ArithmeticOperatorMod, Operand: Param("modulus")}}},
+		QueryFilter{Raw: &RawCondition{SQL: "date(created_at) = ?", Params: []any{Param("raw")}}},
+		cond("id", ComparisonOperatorIn, &Subquery{Table: "orders", DSL: &QueryDSL{Filters: ptr(at("subquery"))}}),
+	)
+	return &QueryDSL{
+		Filters:      &filters,
+		Joins:        []JoinConfiguration{{TargetTable: "orders", On: at("join")}},
+		Aggregations: []AggregationConfiguration{{Type: AggregationTypeSum, Field: "total", Filter: ptr(at("aggregate"))}},
+		Sort:         []SortConfiguration{{Case: caseOn("sort")}},
+		Window:       []WindowFunction{{Function: "LAG", Arguments: []FilterValue{"total", Param("offset")}}},
+		Projection: &ProjectionConfiguration{Computed: []ProjectionComputedItem{
+			{CaseExpression: caseOn("projection")},
+			{CoalesceExpression: &CoalesceExpression{Fields: []string{"nickname"}, Default: Param("default"), Alias: "display"}},
+			{ComputedFieldExpression: &ComputedFieldExpression{Expression: &FunctionCall{Function: "greet", Arguments: []FilterValue{Param("greeting")}}, Alias: "greeting"}},
+		}},
+	}
+}
+
+func ptr(f QueryFilter) *QueryFilter { return &f }
+
+// collectParams returns every Param value reachable from v.
+func collectParams(v reflect.Value, found map[Param]struct{}) {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if !v.IsNil() {
+			if p, ok := v.Interface().(Param); ok {
+				found[p] = struct{}{}
+				return
+			}
+			collectParams(v.Elem(), found)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			collectParams(v.Field(i), found)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			collectParams(v.Index(i), found)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			collectParams(v.MapIndex(key), found)
+		}
+	}
+}
+
+func TestBindParams(t *testing.T) {
+	dsl := paramDSL()
+	found := make(map[Param]struct{})
+	collectParams(reflect.ValueOf(dsl), found)
+	params := make(map[string]any, len(found))
+	for p := range found {
+		params[string(p)] = "bound:" + string(p)
+	}
+
+	bound, err := BindParams(dsl, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	left := make(map[Param]struct{})
+	collectParams(reflect.ValueOf(bound), left)
+	if len(left) > 0 {
+		t.Errorf("unbound params left: %v", left)
+	}
+	if !reflect.DeepEqual(paramDSL(), dsl) {
+		t.Error("BindParams modified its input")
+	}
+
+	conditions := bound.Filters.Group.Conditions
+	if got := conditions[1].Condition.Value; !reflect.DeepEqual(got, []any{"new", "bound:status"}) {
+		t.Errorf("list with a Param: got %#v", got)
+	}
+	if got := conditions[2].Condition.Value; !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("list without Params: got %#v, want its type kept", got)
+	}
+	if got := bound.Projection.Computed[1].CoalesceExpression.Default; got != "bound:default" {
+		t.Errorf("coalesce default: got %v", got)
+	}
+}
+
+func TestBindParamsUnbound(t *testing.T) {
+	for _, missing := range []string{"filters", "subquery", "join", "aggregate", "sort_then", "projection", "raw", "modulus"} {
+		params := map[string]any{}
+		found := make(map[Param]struct{})
+		collectParams(reflect.ValueOf(paramDSL()), found)
+		for p := range found {
+			if string(p) != missing {
+				params[string(p)] = 1
+			}
+		}
+		_, err := BindParams(paramDSL(), params)
+		var unbound ErrUnboundParam
+		if !errors.As(err, &unbound) || unbound.Name != missing {
+			t.Errorf("missing %q: got %v, want ErrUnboundParam", missing, err)
+		}
+	}
+}
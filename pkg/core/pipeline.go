@@ -0,0 +1,136 @@
+package core
+
+import "fmt"
+
+// PipelineStage names one stage of the hybrid query pipeline that executors
+// run for every Query.
+type PipelineStage string
+
+const (
+	// PipelineStageFetch generates and runs the SQL for the standard parts of
+	// the QueryDSL (see SplitFilter) and reads the rows.
+	PipelineStageFetch PipelineStage = "fetch"
+	// PipelineStageGoFilter applies custom Go filter functions.
+	PipelineStageGoFilter PipelineStage = "go_filter"
+	// PipelineStageCompute adds computed fields with Go compute and multi
+	// compute functions.
+	PipelineStageCompute PipelineStage = "compute"
+	// PipelineStageComputedFilter applies filter conditions that reference
+	// computed aliases, using FilterRows.
+	PipelineStageComputedFilter PipelineStage = "computed_filter"
+	// PipelineStageScore adds the ScoreConfiguration's score to the rows that
+	// passed the filters.
+	PipelineStageScore PipelineStage = "score"
+	// PipelineStageComputedSort sorts on computed aliases and scores, using
+	// SortRows.
+	PipelineStageComputedSort PipelineStage = "computed_sort"
+	// PipelineStageWindowCompute runs Go window compute functions over the
+	// rows in their sorted order.
+	PipelineStageWindowCompute PipelineStage = "window_compute"
+	// PipelineStageRequireNonNull drops rows with NULL values for the
+	// projection's RequireNonNull fields.
+	PipelineStageRequireNonNull PipelineStage = "require_non_null"
+	// PipelineStageDistinctOn keeps the first row, in sorted order, of each
+	// combination of the projection's DistinctOn fields.
+	PipelineStageDistinctOn PipelineStage = "distinct_on"
+	// PipelineStageAggregate computes aggregations that reference computed aliases.
+	PipelineStageAggregate PipelineStage = "aggregate"
+	// PipelineStagePaginate applies the limit and offset (or cursor) to rows
+	// that were filtered or sorted in Go.
+	PipelineStagePaginate PipelineStage = "paginate"
+	// PipelineStageRowTransform applies the GoRowTransformFunction.
+	PipelineStageRowTransform PipelineStage = "row_transform"
+	// PipelineStageProjection applies the final ProjectionConfiguration,
+	// including field transforms.
+	PipelineStageProjection PipelineStage = "projection"
+)
+
+// Pipeline is an order in which to run the pipeline stages. Executors run
+// CanonicalPipeline unless configured with another order, which they must
+// check with Validate.
+type Pipeline []PipelineStage
+
+// stageDependencies lists, for each stage, the stages whose output it needs
+// and that must therefore run before it.
+var stageDependencies = map[PipelineStage][]PipelineStage{
+	PipelineStageFetch:          nil,
+	PipelineStageGoFilter:       {PipelineStageFetch},
+	PipelineStageCompute:        {PipelineStageFetch},
+	PipelineStageComputedFilter: {PipelineStageCompute},
+	PipelineStageScore:          {PipelineStageGoFilter, PipelineStageComputedFilter},
+	PipelineStageComputedSort:   {PipelineStageCompute, PipelineStageScore},
+	PipelineStageWindowCompute:  {PipelineStageComputedSort},
+	PipelineStageRequireNonNull: {PipelineStageCompute, PipelineStageWindowCompute},
+	PipelineStageDistinctOn:     {PipelineStageComputedSort},
+	PipelineStageAggregate: {
+		PipelineStageGoFilter, PipelineStageComputedFilter,
+		PipelineStageRequireNonNull, PipelineStageDistinctOn,
+	},
+	PipelineStagePaginate: {
+		PipelineStageGoFilter, PipelineStageComputedFilter, PipelineStageComputedSort,
+		PipelineStageRequireNonNull, PipelineStageDistinctOn,
+	},
+	PipelineStageRowTransform: {
+		PipelineStageCompute, PipelineStageComputedFilter, PipelineStageComputedSort,
+	},
+	PipelineStageProjection: {
+		PipelineStageGoFilter, PipelineStageCompute, PipelineStageComputedFilter,
+		PipelineStageScore, PipelineStageComputedSort, PipelineStageWindowCompute,
+		PipelineStageRequireNonNull, PipelineStageDistinctOn, PipelineStageAggregate,
+		PipelineStagePaginate, PipelineStageRowTransform,
+	},
+}
+
+// CanonicalPipeline returns the order in which executors run the pipeline
+// stages by default. Each stage only sees the output of the stages before it,
+// so a computed alias can be filtered on, sorted by and aggregated, but a Go
+// filter function cannot see computed fields.
+func CanonicalPipeline() Pipeline {
+	return Pipeline{
+		PipelineStageFetch,
+		PipelineStageGoFilter,
+		PipelineStageCompute,
+		PipelineStageComputedFilter,
+		PipelineStageScore,
+		PipelineStageComputedSort,
+		PipelineStageWindowCompute,
+		PipelineStageRequireNonNull,
+		PipelineStageDistinctOn,
+		PipelineStageAggregate,
+		PipelineStagePaginate,
+		PipelineStageRowTransform,
+		PipelineStageProjection,
+	}
+}
+
+// Validate checks that the pipeline lists every stage exactly once and runs
+// each stage after the stages whose output it needs: for example, window
+// computes must follow the sort, aggregation and pagination must follow every
+// stage that drops rows, row transforms must follow the stages that read the
+// fields they may rename, and projection must come last. Within those
+// constraints stages may be reordered, e.g. to aggregate only the current page.
+func (p Pipeline) Validate() error {
+	position := make(map[PipelineStage]int, len(p))
+	for i, stage := range p {
+		if _, ok := stageDependencies[stage]; !ok {
+			return fmt.Errorf("unknown pipeline stage: %s", stage)
+		}
+		if _, dup := position[stage]; dup {
+			return fmt.Errorf("pipeline stage %s listed more than once", stage)
+		}
+		position[stage] = i
+	}
+	for _, stage := range CanonicalPipeline() {
+		if _, ok := position[stage]; !ok {
+			return fmt.Errorf("pipeline is missing stage %s", stage)
+		}
+	}
+	for i, stage := range p {
+		for _, dep := range stageDependencies[stage] {
+			if position[dep] > i {
+				return fmt.Errorf("pipeline stage %s must run after %s", stage, dep)
+			}
+		}
+	}
+	return nil
+}
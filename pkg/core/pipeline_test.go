@@ -0,0 +1,199 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestPipelineValidate(t *testing.T) {
+	if err := CanonicalPipeline().Validate(); err != nil {
+		t.Fatalf("canonical pipeline: %v", err)
+	}
+
+	// move returns the canonical pipeline with stage moved to just after "after".
+	move := func(stage, after PipelineStage) Pipeline {
+		var p Pipeline
+		for _, s := range CanonicalPipeline() {
+			if s != stage {
+				p = append(p, s)
+			}
+			if s == after {
+				p = append(p, stage)
+			}
+		}
+		return p
+	}
+	valid := map[string]Pipeline{
+		"aggregate the page":            move(PipelineStageAggregate, PipelineStagePaginate),
+		"transform before windows":      move(PipelineStageRowTransform, PipelineStageComputedSort),
+		"distinct before non-null rows": move(PipelineStageRequireNonNull, PipelineStageDistinctOn),
+	}
+	for name, p := range valid {
+		if err := p.Validate(); err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+	}
+
+	invalid := map[string]Pipeline{
+		"window before sort":        move(PipelineStageWindowCompute, PipelineStageScore),
+		"sort before score":         move(PipelineStageScore, PipelineStageWindowCompute),
+		"paginate before filter":    move(PipelineStagePaginate, PipelineStageFetch),
+		"projection not last":       move(PipelineStageProjection, PipelineStageAggregate),
+		"computed filter early":     move(PipelineStageComputedFilter, PipelineStageFetch),
+		"missing stage":             CanonicalPipeline()[1:],
+		"duplicate stage":           append(CanonicalPipeline(), PipelineStageFetch),
+		"unknown stage":             append(CanonicalPipeline(), "cache"),
+		"distinct before sorting":   move(PipelineStageDistinctOn, PipelineStageScore),
+		"aggregate before non-null": move(PipelineStageAggregate, PipelineStageWindowCompute),
+		"aggregate before distinct": move(PipelineStageAggregate, PipelineStageRequireNonNull),
+		"transform before filter":   move(PipelineStageRowTransform, PipelineStageCompute),
+		"transform before sorting":  move(PipelineStageRowTransform, PipelineStageScore),
+	}
+	for name, p := range invalid {
+		if err := p.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// runPipeline applies the stages in order to rows, the way an executor would
+// after fetching them, using the package's Go-side helpers. Queries sort by
+// score then total, keep one row per region and paginate two rows per page.
+func runPipeline(p Pipeline, rows []Row) (page []Row, aggregates map[string]any, err error) {
+	opts := EvalOptions{Filters: map[ComparisonOperator]GoFilterFunction{
+		"in_stock": func(row Row) (bool, error) { return row["stock"].(int64) > 0, nil },
+	}}
+	goFilter := cond("id", "in_stock", nil)
+	computedFilter := cond("total", ComparisonOperatorGte, 10)
+	sort := []SortConfiguration{{Field: "__score", Direction: SortDirectionDesc}, {Field: "total", Direction: SortDirectionDesc}}
+
+	for _, stage := range p {
+		switch stage {
+		case PipelineStageFetch:
+		case PipelineStageGoFilter:
+			rows, err = FilterRows(rows, &goFilter, opts)
+		case PipelineStageCompute:
+			for _, row := range rows {
+				if qty, ok := row["qty"].(int64); ok {
+					row["total"] = row["price"].(int64) * qty
+				} else {
+					row["total"] = nil
+				}
+			}
+		case PipelineStageComputedFilter:
+			rows, err = FilterRows(rows, &computedFilter, opts)
+		case PipelineStageScore:
+			for _, row := range rows {
+				row["__score"] = float64(len(row["name"].(string)))
+			}
+		case PipelineStageComputedSort:
+			err = SortRows(rows, sort, opts)
+		case PipelineStageWindowCompute:
+			var running int64
+			for _, row := range rows {
+				running += row["total"].(int64)
+				row["running"] = running
+			}
+		case PipelineStageRequireNonNull:
+			kept := rows[:0]
+			for _, row := range rows {
+				if row["total"] != nil {
+					kept = append(kept, row)
+				}
+			}
+			rows = kept
+		case PipelineStageDistinctOn:
+			seen := map[any]bool{}
+			kept := rows[:0]
+			for _, row := range rows {
+				if !seen[row["region"]] {
+					seen[row["region"]] = true
+					kept = append(kept, row)
+				}
+			}
+			rows = kept
+		case PipelineStageAggregate:
+			var sum int64
+			for _, row := range rows {
+				sum += row["total"].(int64)
+			}
+			aggregates = map[string]any{"count": int64(len(rows)), "sum_total": sum, "argmax_total": ExtremeRow(rows, "total", true)["id"]}
+		case PipelineStagePaginate:
+			if len(rows) > 2 {
+				rows = rows[:2]
+			}
+		case PipelineStageRowTransform:
+			for _, row := range rows {
+				row["name"] = fmt.Sprintf("#%v %v", row["id"], row["name"])
+			}
+		case PipelineStageProjection:
+			for i, row := range rows {
+				rows[i] = Row{"id": row["id"], "name": row["name"], "total": row["total"], "running": row["running"]}
+			}
+		default:
+			return nil, nil, fmt.Errorf("unexpected stage %s", stage)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("stage %s: %w", stage, err)
+		}
+	}
+	return rows, aggregates, nil
+}
+
+func pipelineRows() []Row {
+	return []Row{
+		{"id": int64(1), "name": "desk", "region": "eu", "price": int64(100), "qty": int64(1), "stock": int64(3)},
+		{"id": int64(2), "name": "lamp", "region": "eu", "price": int64(20), "qty": int64(2), "stock": int64(1)},
+		{"id": int64(3), "name": "pen", "region": "us", "price": int64(2), "qty": int64(3), "stock": int64(9)},
+		{"id": int64(4), "name": "chair", "region": "us", "price": int64(50), "qty": int64(1), "stock": int64(0)},
+		{"id": int64(5), "name": "sofa", "region": "asia", "price": int64(300), "qty": int64(1), "stock": int64(2)},
+		{"id": int64(6), "name": "rug", "region": "us", "price": int64(15), "qty": nil, "stock": int64(5)},
+		{"id": int64(7), "name": "shelf", "region": "us", "price": int64(40), "qty": int64(1), "stock": int64(4)},
+	}
+}
+
+func TestCanonicalPipelineRun(t *testing.T) {
+	page, aggregates, err := runPipeline(CanonicalPipeline(), pipelineRows())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// chair is out of stock, pen's total is below 10 and rug's total is NULL.
+	// By score then total: shelf(5), sofa(4), desk(4), lamp(4); one per region
+	// leaves shelf, sofa and desk, and the first page holds two of them.
+	want := []Row{
+		{"id": int64(7), "name": "#7 shelf", "total": int64(40), "running": int64(40)},
+		{"id": int64(5), "name": "#5 sofa", "total": int64(300), "running": int64(340)},
+	}
+	if !reflect.DeepEqual(page, want) {
+		t.Errorf("page:\n got %v\nwant %v", page, want)
+	}
+	wantAggregates := map[string]any{"count": int64(3), "sum_total": int64(440), "argmax_total": int64(5)}
+	if !reflect.DeepEqual(aggregates, wantAggregates) {
+		t.Errorf("aggregates: got %v, want %v", aggregates, wantAggregates)
+	}
+}
+
+func TestReorderedPipelineRun(t *testing.T) {
+	var p Pipeline
+	for _, stage := range CanonicalPipeline() {
+		if stage == PipelineStageAggregate {
+			continue
+		}
+		p = append(p, stage)
+		if stage == PipelineStagePaginate {
+			p = append(p, PipelineStageAggregate)
+		}
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	_, aggregates, err := runPipeline(p, pipelineRows())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantAggregates := map[string]any{"count": int64(2), "sum_total": int64(340), "argmax_total": int64(5)}
+	if !reflect.DeepEqual(aggregates, wantAggregates) {
+		t.Errorf("page aggregates: got %v, want %v", aggregates, wantAggregates)
+	}
+}
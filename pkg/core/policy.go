@@ -0,0 +1,75 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+// QueryPolicy inspects the structure of a QueryDSL before it is executed and
+// returns an error (typically ErrPolicyViolation) for queries likely to be too
+// expensive to run against a shared database.
+type QueryPolicy interface {
+	Check(dsl *QueryDSL) error
+}
+
+// QueryPolicyFunc adapts a function to the QueryPolicy interface.
+type QueryPolicyFunc func(dsl *QueryDSL) error
+
+func (f QueryPolicyFunc) Check(dsl *QueryDSL) error {
+	return f(dsl)
+}
+
+// RequireFilterOrLimit rejects queries that have neither a filter condition
+// nor a pagination limit, since they read and return the whole table. Empty
+// filters and groups without any condition do not count as a filter, and a
+// nil QueryDSL is rejected.
+func RequireFilterOrLimit() QueryPolicy {
+	return QueryPolicyFunc(func(dsl *QueryDSL) error {
+		if dsl != nil && (hasCondition(dsl.Filters) || (dsl.Pagination != nil && dsl.Pagination.Limit > 0)) {
+			return nil
+		}
+		return ErrPolicyViolation{
+			Policy: "require_filter_or_limit",
+			Reason: "query has no filters and no pagination limit",
+		}
+	})
+}
+
+// errConditionFound stops hasCondition's walk at the first condition.
+var errConditionFound = errors.New("condition found")
+
+// hasCondition reports whether the filter tree contains at least one
+// condition or raw condition.
+func hasCondition(filter *QueryFilter) bool {
+	return walkFilter(filter, func(f *QueryFilter) error {
+		if f.Condition != nil || f.Raw != nil {
+			return errConditionFound
+		}
+		return nil
+	}) != nil
+}
+
+// NoLeadingWildcard rejects conditions rendered as a LIKE pattern starting
+// with a wildcard (contains, ncontains and endswith), which cannot use an
+// index. Every nested filter is checked, including join conditions,
+// aggregation filters, CASE conditions and subqueries (see WalkFilters).
+func NoLeadingWildcard() QueryPolicy {
+	return QueryPolicyFunc(func(dsl *QueryDSL) error {
+		return WalkFilters(dsl, checkLeadingWildcard)
+	})
+}
+
+func checkLeadingWildcard(filter *QueryFilter) error {
+	c := filter.Condition
+	if c == nil {
+		return nil
+	}
+	switch c.Operator {
+	case ComparisonOperatorContains, ComparisonOperatorNotContains, ComparisonOperatorEndsWith:
+		return ErrPolicyViolation{
+			Policy: "no_leading_wildcard",
+			Reason: fmt.Sprintf("operator %q on field %q requires a full scan", c.Operator, c.Field),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNoLeadingWildcard(t *testing.T) {
+	policy := NoLeadingWildcard()
+	for location, dsl := range nestedFilterDSL(ComparisonOperatorEndsWith) {
+		var violation ErrPolicyViolation
+		if err := policy.Check(dsl); !errors.As(err, &violation) || violation.Policy != "no_leading_wildcard" {
+			t.Errorf("%s: got %v, want a no_leading_wildcard violation", location, err)
+		}
+	}
+	for location, dsl := range nestedFilterDSL(ComparisonOperatorStartsWith) {
+		if err := policy.Check(dsl); err != nil {
+			t.Errorf("%s: unexpected error %v", location, err)
+		}
+	}
+}
+
+func TestRequireFilterOrLimit(t *testing.T) {
+	policy := RequireFilterOrLimit()
+	age := cond("age", ComparisonOperatorGt, 30)
+	tests := []struct {
+		name    string
+		dsl     *QueryDSL
+		wantErr bool
+	}{
+		{"unfiltered, unlimited", &QueryDSL{}, true},
+		{"nil QueryDSL", nil, true},
+		{"empty filter", &QueryDSL{Filters: &QueryFilter{}}, true},
+		{"empty AND group", &QueryDSL{Filters: &QueryFilter{Group: &FilterGroup{Operator: LogicalOperatorAnd}}}, true},
+		{"nested empty groups", &QueryDSL{Filters: &QueryFilter{Group: &FilterGroup{Operator: LogicalOperatorOr, Conditions: []QueryFilter{group(LogicalOperatorAnd)}}}}, true},
+		{"zero limit", &QueryDSL{Pagination: &PaginationOptions{}}, true},
+		{"condition", &QueryDSL{Filters: &age}, false},
+		{"nested condition", &QueryDSL{Filters: &QueryFilter{Group: &FilterGroup{Operator: LogicalOperatorAnd, Conditions: []QueryFilter{group(LogicalOperatorOr, age)}}}}, false},
+		{"raw condition", &QueryDSL{Filters: &QueryFilter{Raw: &RawCondition{SQL: "age > 30"}}}, false},
+		{"limit", &QueryDSL{Pagination: &PaginationOptions{Limit: 10}}, false},
+	}
+	for _, tt := range tests {
+		err := policy.Check(tt.dsl)
+		var violation ErrPolicyViolation
+		if tt.wantErr && !errors.As(err, &violation) {
+			t.Errorf("%s: got %v, want a require_filter_or_limit violation", tt.name, err)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+	}
+}
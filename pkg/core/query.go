@@ -11,6 +11,20 @@ type QueryGenerator interface {
     // parameters from a map of updates and a QueryFilter for the WHERE clause.
     GenerateUpdateSQL(updates map[string]any, filters *QueryFilter) (string, []any, error)
 
+    // GenerateLimitedUpdateSQL creates a SQL UPDATE query that only updates the
+    // first limit matching rows in the given sort order (UPDATE ... ORDER BY
+    // ... LIMIT), e.g. to process the oldest items of a queue in batches. It
+    // returns ErrUnsupportedFeature when the database build lacks the syntax.
+    GenerateLimitedUpdateSQL(updates map[string]any, filters *QueryFilter, sort []SortConfiguration, limit int) (string, []any, error)
+
+    // GenerateUpdateFromSQL creates a SQL UPDATE query whose WHERE clause may
+    // reference another table or subquery described by source, rendered as
+    // UPDATE ... SET ... FROM <source> WHERE <source.On> AND <filters>.
+    // Positional parameters follow that textual order: those of the SET
+    // clause first, then the source subquery's, then source.On's, and finally
+    // those of filters.
+    GenerateUpdateFromSQL(updates map[string]any, source *UpdateSource, filters *QueryFilter) (string, []any, error)
+
     // GenerateInsertSQL creates a SQL INSERT query string and its corresponding
     // parameters from a slice of records (maps of field names to values).
     // Supports both single and batch inserts.
@@ -20,5 +34,7 @@ type QueryGenerator interface {
     // parameters from a QueryFilter for the WHERE clause.
     // By default, requires a WHERE clause for safety. Set unsafeDelete to true
     // to allow deletion without WHERE clause (deletes all records).
+    // Conditions may use a Subquery value (e.g. user_id in the ids of inactive
+    // users); such a condition counts as a WHERE clause for the safety check.
     GenerateDeleteSQL(filters *QueryFilter, unsafeDelete bool) (string, []any, error)
 }
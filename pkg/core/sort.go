@@ -0,0 +1,123 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SortRows sorts rows in place in Go, following the same ordering rules as
+// the generated SQL: NULLs sort first in ascending order, numbers before
+// text, and collations apply to text. Executors use it to sort on computed
+// aliases and on CASE priorities that reference custom operators, which are
+// resolved from opts like in FilterRows; batch filter functions are called
+// once with all of rows. The sort is stable, so rows that tie on every sort
+// field keep their relative order.
+func SortRows(rows []Row, sortConfig []SortConfiguration, opts EvalOptions) error {
+	if len(sortConfig) == 0 {
+		return nil
+	}
+	for _, s := range sortConfig {
+		if !s.Collation.IsValid() {
+			return fmt.Errorf("unknown collation: %s", s.Collation)
+		}
+		if !s.Cast.IsValid() {
+			return fmt.Errorf("unknown cast type: %s", s.Cast)
+		}
+	}
+
+	var whens []*QueryFilter
+	for _, s := range sortConfig {
+		if s.Case != nil {
+			for i := range s.Case.Cases {
+				whens = append(whens, &s.Case.Cases[i].When)
+			}
+		}
+	}
+	e, err := newRowEvaluator(rows, opts, whens...)
+	if err != nil {
+		return err
+	}
+
+	// Resolve every sort key up front so evaluation errors surface before sorting.
+	keys := make([][]any, len(rows))
+	for i, row := range rows {
+		keys[i] = make([]any, len(sortConfig))
+		for j, s := range sortConfig {
+			key, err := sortKey(e, i, row, s)
+			if err != nil {
+				return err
+			}
+			keys[i][j] = key
+		}
+	}
+
+	indices := make([]int, len(rows))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(a, b int) bool {
+		ka, kb := keys[indices[a]], keys[indices[b]]
+		for j, s := range sortConfig {
+			c := compareSortKeys(ka[j], kb[j])
+			if c == 0 {
+				continue
+			}
+			if s.Direction == SortDirectionDesc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+
+	sorted := make([]Row, len(rows))
+	for i, idx := range indices {
+		sorted[i] = rows[idx]
+	}
+	copy(rows, sorted)
+	return nil
+}
+
+func sortKey(e *rowEvaluator, i int, row Row, s SortConfiguration) (any, error) {
+	if s.Case != nil {
+		for j := range s.Case.Cases {
+			t, err := e.truth(i, row, &s.Case.Cases[j].When)
+			if err != nil {
+				return nil, err
+			}
+			if t == TruthTrue {
+				return s.Case.Cases[j].Then, nil
+			}
+		}
+		return s.Case.Else, nil
+	}
+	value, err := driverValue(row[s.Field])
+	if err != nil {
+		return nil, fmt.Errorf("field %s: %w", s.Field, err)
+	}
+	if s.Cast != "" {
+		cast, err := castValue(value, s.Cast)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", s.Field, err)
+		}
+		value = cast
+	}
+	if s.Collation != "" {
+		value = collate(value, s.Collation)
+	}
+	return value, nil
+}
+
+// compareSortKeys extends compareValues with NULL ordering: NULL sorts
+// before every other value.
+func compareSortKeys(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	return compareValues(a, b)
+}
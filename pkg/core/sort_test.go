@@ -0,0 +1,88 @@
+package core
+
+import "testing"
+
+func TestSortRows(t *testing.T) {
+	tests := []struct {
+		name string
+		sort []SortConfiguration
+		want []int64
+	}{
+		{"nulls first ascending", []SortConfiguration{{Field: "age", Direction: SortDirectionAsc}}, []int64{3, 1, 2, 4}},
+		{"nulls last descending", []SortConfiguration{{Field: "age", Direction: SortDirectionDesc}}, []int64{4, 2, 1, 3}},
+		{"nocase", []SortConfiguration{{Field: "name", Direction: SortDirectionAsc, Collation: CollationNoCase}}, []int64{4, 1, 2, 3}},
+		{"binary", []SortConfiguration{{Field: "name", Direction: SortDirectionAsc}}, []int64{4, 2, 1, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := append([]Row(nil), filterTestRows...)
+			if err := SortRows(rows, tt.sort, EvalOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			if !equalIDs(ids(rows), tt.want) {
+				t.Errorf("got ids %v, want %v", ids(rows), tt.want)
+			}
+		})
+	}
+}
+
+func TestSortRowsBatchCase(t *testing.T) {
+	calls := 0
+	opts := EvalOptions{BatchFilters: map[ComparisonOperator]GoBatchFilterFunction{
+		"flagged": func(rows []Row) ([]bool, error) {
+			calls++
+			results := make([]bool, len(rows))
+			for i, row := range rows {
+				results[i] = row["id"].(int64) == 3
+			}
+			return results, nil
+		},
+	}}
+	sort := []SortConfiguration{
+		{Direction: SortDirectionAsc, Case: &CaseExpression{
+			Cases: []CaseCondition{{When: cond("id", "flagged", nil), Then: 0}},
+			Else:  1,
+		}},
+		{Field: "id", Direction: SortDirectionDesc},
+	}
+	rows := append([]Row(nil), filterTestRows...)
+	if err := SortRows(rows, sort, opts); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{3, 4, 2, 1}; !equalIDs(ids(rows), want) {
+		t.Errorf("got ids %v, want %v", ids(rows), want)
+	}
+	if calls != 1 {
+		t.Errorf("batch function called %d times, want 1", calls)
+	}
+}
+
+func TestSortRowsCastNumericText(t *testing.T) {
+	rows := []Row{
+		{"id": int64(1), "code": "10"},
+		{"id": int64(2), "code": "9"},
+		{"id": int64(3), "code": "100"},
+		{"id": int64(4), "code": "12abc"},
+	}
+	tests := []struct {
+		name string
+		cast CastType
+		want []int64
+	}{
+		// ORDER BY "code"
+		{"text order", "", []int64{1, 3, 4, 2}},
+		// ORDER BY CAST("code" AS INTEGER)
+		{"integer cast", CastTypeInteger, []int64{2, 1, 4, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted := append([]Row(nil), rows...)
+			if err := SortRows(sorted, []SortConfiguration{{Field: "code", Direction: SortDirectionAsc, Cast: tt.cast}}, EvalOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			if !equalIDs(ids(sorted), tt.want) {
+				t.Errorf("got ids %v, want %v", ids(sorted), tt.want)
+			}
+		})
+	}
+}
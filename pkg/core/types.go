@@ -1,5 +1,7 @@
 package core
 
+import "time"
+
 // Ensure these match your actual type definitions from the DSL.
 // For example, if you have these in a 'querydsl.go' or 'types.go' file.
 
@@ -37,12 +39,52 @@ const (
 	// Deprecated: use ComparisonOperatorNotExists instead
 	ComparisonOperatorNExists    ComparisonOperator = "nexists"
 	ComparisonOperatorNotExists    ComparisonOperator = "nexists"
+	// NULL-safe equality: NULL IS NULL is true, unlike NULL = NULL.
+	ComparisonOperatorIs         ComparisonOperator = "is"
+	ComparisonOperatorIsNot      ComparisonOperator = "isnot"
+	// Approximate equality for REAL columns: Value is [value, epsilon] and the
+	// condition renders as ABS(field - ?) <= ?.
+	ComparisonOperatorApproxEq   ComparisonOperator = "approxeq"
 )
 
 
 // FilterValue represents the supported data types for filter values.
 type FilterValue any // Can be string, number, bool, []any
 
+// ColumnRef is a FilterValue that references another column instead of a
+// literal, so a condition can compare two columns (e.g. "balance" > "credit_limit").
+// It is rendered as a quoted identifier and never bound as a parameter.
+type ColumnRef struct {
+	Name string // The referenced column, optionally table-qualified
+}
+
+// Param is a FilterValue naming a parameter supplied at execution time, so a
+// QueryDSL can be defined once and run with different inputs, e.g.
+// Value: Param("minAge"). See BindParams.
+type Param string
+
+// SQLFunc is a FilterValue naming a database-evaluated value, such as the
+// server's current time. It is rendered inline instead of being bound, so
+// only the whitelisted functions below are accepted.
+type SQLFunc string
+
+const (
+	SQLFuncNow              SQLFunc = "NOW"               // Current date and time, e.g. datetime('now') in SQLite
+	SQLFuncCurrentDate      SQLFunc = "CURRENT_DATE"      // Current date
+	SQLFuncCurrentTimestamp SQLFunc = "CURRENT_TIMESTAMP" // Current date and time
+)
+
+// BooleanBinding selects how boolean filter values are bound as parameters,
+// for schemas that store booleans as something other than 0/1. Executors
+// take it as a per-executor setting, apply Bind to condition values and pass
+// it in EvalOptions so that Go evaluation matches the same rows.
+type BooleanBinding string
+
+const (
+	BooleanBindingInt    BooleanBinding = "int"    // true/false bound as 1/0 (default)
+	BooleanBindingString BooleanBinding = "string" // true/false bound as "true"/"false"
+)
+
 // FunctionCall represents a call to a function (either SQL or Go-based).
 type FunctionCall struct {
 	Function  FilterValue   // The name/identifier of the function (e.g., "DATE_SUB", "full_name")
@@ -51,9 +93,77 @@ type FunctionCall struct {
 
 // FilterCondition defines a single filtering condition.
 type FilterCondition struct {
-	Field    string             // The field to filter on
-	Operator ComparisonOperator // The comparison operator (e.g., "eq", "gt", "is_adult")
-	Value    FilterValue        // The value to compare against
+	Field     string             // The field to filter on
+	Operator  ComparisonOperator // The comparison operator (e.g., "eq", "gt", "is_adult")
+	Value     FilterValue        // The value to compare against
+	Collation Collation          `json:",omitempty"` // Optional collating sequence for text comparisons
+	// IncludeNulls also matches rows where Field is NULL, rendering e.g.
+	// (field > ? OR field IS NULL). By default NULLs are excluded, matching
+	// SQL's three-valued logic.
+	IncludeNulls bool `json:",omitempty"`
+	// Expression optionally transforms Field before comparing, e.g.
+	// ("id" % ?) = ? to select even ids.
+	Expression *FieldExpression `json:",omitempty"`
+	Cast       CastType         `json:",omitempty"` // Optional CAST applied to Field before comparing
+}
+
+// CastType is the target type of a CAST, one of SQLite's storage classes.
+type CastType string
+
+const (
+	CastTypeInteger CastType = "INTEGER"
+	CastTypeReal    CastType = "REAL"
+	CastTypeText    CastType = "TEXT"
+	CastTypeBlob    CastType = "BLOB"
+	CastTypeNumeric CastType = "NUMERIC"
+)
+
+// ArithmeticOperator for field expressions.
+type ArithmeticOperator string
+
+const (
+	ArithmeticOperatorAdd ArithmeticOperator = "+"
+	ArithmeticOperatorSub ArithmeticOperator = "-"
+	ArithmeticOperatorMul ArithmeticOperator = "*"
+	ArithmeticOperatorDiv ArithmeticOperator = "/"
+	ArithmeticOperatorMod ArithmeticOperator = "%"
+)
+
+// FieldFunction is a scalar function applied to a field in a FieldExpression.
+type FieldFunction string
+
+const (
+	// FieldFunctionLength is the character length of a string field, LENGTH("field").
+	// Evaluated in Go, non-string values are an error.
+	FieldFunctionLength FieldFunction = "length"
+)
+
+// FieldExpression transforms the left side of a FilterCondition with a scalar
+// function and/or an arithmetic operation with a constant operand. When both
+// are set the function is applied first, e.g. (LENGTH("name") % ?). The
+// operand is bound as a parameter.
+type FieldExpression struct {
+	Function FieldFunction      `json:",omitempty"` // Optional function applied to the field
+	Operator ArithmeticOperator `json:",omitempty"` // "+", "-", "*", "/" or "%"
+	Operand  FilterValue        `json:",omitempty"` // The constant right-hand operand (e.g. 2 in "id" % 2)
+}
+
+// Subquery can be used as a FilterCondition value to compare a field against
+// the result of a nested SELECT. When the DSL declares a single aggregation it
+// renders as a scalar aggregate, e.g.
+// (SELECT COUNT(*) FROM orders WHERE orders.user_id = users.id) > ?.
+// With the in/nin operators and a single projected field it renders as a set,
+// e.g. "user_id" IN (SELECT "id" FROM users WHERE "is_active" = ?).
+type Subquery struct {
+	Table        string                // The table the subquery reads from
+	DSL          *QueryDSL             // Filters, projection and aggregations of the subquery
+	Correlations []SubqueryCorrelation `json:",omitempty"` // Links to the outer query's row
+}
+
+// SubqueryCorrelation equates a subquery field with a field of the outer query.
+type SubqueryCorrelation struct {
+	Field      string // Field of the subquery's table (e.g. "user_id")
+	OuterField string // Field of the outer query's table (e.g. "id")
 }
 
 // FilterGroup combines multiple conditions with a logical operator.
@@ -62,10 +172,20 @@ type FilterGroup struct {
 	Conditions []QueryFilter // Nested filter conditions or groups
 }
 
+// RawCondition is a SQL fragment spliced verbatim into the WHERE clause, for
+// predicates the DSL cannot model. It bypasses identifier quoting and
+// validation, so executors only accept it when raw conditions are explicitly
+// allowed. Its "?" placeholders must match Params, which are bound in place.
+type RawCondition struct {
+	SQL    string // e.g. "date(created_at) = date('now', ?)"
+	Params []any  `json:",omitempty"`
+}
+
 // QueryFilter represents a filter condition or a group of conditions.
 type QueryFilter struct {
 	Condition *FilterCondition `json:",omitempty"` // Single condition
 	Group     *FilterGroup     `json:",omitempty"` // Group of conditions
+	Raw       *RawCondition    `json:",omitempty"` // Raw SQL condition
 }
 
 // SortDirection for sorting order.
@@ -80,14 +200,32 @@ const (
 type SortConfiguration struct {
 	Field     string        // The field to sort by
 	Direction SortDirection // "asc" or "desc"
+	Collation Collation     `json:",omitempty"` // Optional collating sequence (e.g. case-insensitive sorting)
+	Cast      CastType      `json:",omitempty"` // Optional CAST, e.g. to sort numeric strings numerically
+	// Case sorts by a computed priority instead of Field. It is rendered as a
+	// native ORDER BY CASE WHEN ... END when all its conditions are standard,
+	// and applied as a Go sort otherwise.
+	Case *CaseExpression `json:",omitempty"`
 }
 
+// Collation names a collating sequence used for text sorting and comparisons.
+type Collation string
+
+const (
+	CollationBinary Collation = "BINARY"
+	CollationNoCase Collation = "NOCASE"
+	CollationRTrim  Collation = "RTRIM"
+)
+
 // PaginationOptions for controlling query results.
 type PaginationOptions struct {
 	Type   string  // "offset" or "cursor"
 	Limit  int     // Maximum number of records to return
 	Offset *int    `json:",omitempty"` // For offset-based pagination
 	Cursor *string `json:",omitempty"` // For cursor-based pagination
+	// WithTies also returns every row tied with the last row on the sort
+	// fields, like FETCH FIRST n ROWS WITH TIES, so a page may exceed Limit.
+	WithTies bool `json:",omitempty"`
 	// Additional fields for cursor-based pagination (e.g., fields, directions)
 	// would go here if you expand it.
 }
@@ -95,6 +233,9 @@ type PaginationOptions struct {
 // ProjectionField defines a field to include/exclude in the projection.
 type ProjectionField struct {
 	Name   string                 // The name of the field
+	Alias  string                 `json:",omitempty"` // Optional output name; lets the same column be projected more than once
+	Cast   CastType               `json:",omitempty"` // Optional CAST applied to the projected value
+	Transform string              `json:",omitempty"` // Optional registered field transform applied to the value, keeping the field name
 	Nested *ProjectionConfiguration `json:",omitempty"` // For nested projections
 }
 
@@ -119,10 +260,19 @@ type CaseExpression struct {
 	Alias string          // Alias for the case expression result
 }
 
-// ProjectionComputedItem can be either a ComputedFieldExpression or a CaseExpression.
+// CoalesceExpression projects the first non-NULL value among several fields,
+// rendered as COALESCE("nickname", "first_name", ?) AS "display_name".
+type CoalesceExpression struct {
+	Fields  []string    // Fields to try, in order
+	Default FilterValue `json:",omitempty"` // Optional literal used when all fields are NULL; bound as a parameter
+	Alias   string      // Alias for the resulting value
+}
+
+// ProjectionComputedItem can be a ComputedFieldExpression, a CaseExpression or a CoalesceExpression.
 type ProjectionComputedItem struct {
 	ComputedFieldExpression *ComputedFieldExpression `json:",omitempty"`
 	CaseExpression          *CaseExpression          `json:",omitempty"`
+	CoalesceExpression      *CoalesceExpression      `json:",omitempty"`
 }
 
 // ProjectionConfiguration defines which fields to include/exclude and computed fields.
@@ -130,6 +280,21 @@ type ProjectionConfiguration struct {
 	Include  []ProjectionField        `json:",omitempty"` // Fields to include
 	Exclude  []ProjectionField        `json:",omitempty"` // Fields to exclude
 	Computed []ProjectionComputedItem `json:",omitempty"` // Computed fields
+	// DistinctOn keeps only the first row for each distinct combination of
+	// these fields, where "first" follows the query's sort order
+	// (PipelineStageDistinctOn, after sorting and before pagination).
+	DistinctOn []string `json:",omitempty"`
+	// RequireNonNull drops rows whose value for any of these fields is NULL
+	// (or missing) after compute functions have run and before pagination
+	// (PipelineStageRequireNonNull), e.g. rows where a compute returned nil for
+	// unparseable data. Compute errors still abort the query; only nil results
+	// are dropped.
+	RequireNonNull []string `json:",omitempty"`
+	// FlattenJoins returns joined tables' fields as flat columns prefixed with
+	// the join alias (e.g. "order_total" for field "total" of join alias
+	// "order") instead of nested maps, for flat exports such as CSV. Base
+	// table fields are prefixed with QueryDSL.Alias when it is set.
+	FlattenJoins bool `json:",omitempty"`
 }
 
 // JoinType for join operations.
@@ -143,14 +308,26 @@ const (
 )
 
 // JoinConfiguration defines a join operation.
+// TargetTable may be the base table itself (a self-join, e.g. employees to
+// their managers) as long as Alias differs from QueryDSL.Alias; field
+// references in On and projections must then be alias-qualified.
 type JoinConfiguration struct {
 	Type       JoinType      // "inner", "left", etc.
 	TargetTable string        // The table to join with
 	On         QueryFilter // Join condition
-	Alias      string        // Alias for the joined table
+	Alias      string        // Alias for the joined table (required for self-joins)
 	Projection *ProjectionConfiguration `json:",omitempty"` // Projection for the joined table
 }
 
+// UpdateSource defines an additional table or subquery that an UPDATE can
+// reference in its WHERE clause (rendered as UPDATE ... FROM, SQLite 3.33+).
+type UpdateSource struct {
+	TargetTable string      // The table to read from
+	Subquery    *QueryDSL   `json:",omitempty"` // Optional query over TargetTable, used as a derived table
+	Alias       string      // Alias used to reference the source in filters
+	On          QueryFilter // Condition linking the source to the updated table
+}
+
 // AggregationType for aggregation functions.
 type AggregationType string
 
@@ -160,13 +337,19 @@ const (
 	AggregationTypeAvg   AggregationType = "avg"
 	AggregationTypeMin   AggregationType = "min"
 	AggregationTypeMax   AggregationType = "max"
+	// ArgMax and ArgMin return the full row holding the extreme value of
+	// Field rather than the value itself (via SQLite's bare-column MAX/MIN).
+	AggregationTypeArgMax AggregationType = "argmax"
+	AggregationTypeArgMin AggregationType = "argmin"
 )
 
 // AggregationConfiguration defines an aggregation operation.
 type AggregationConfiguration struct {
-	Type  AggregationType // "count", "sum", "avg", etc.
-	Field string          // The field to aggregate
-	Alias string          // Alias for the aggregation result
+	Type   AggregationType // "count", "sum", "avg", etc.
+	Field  string          // The field to aggregate
+	Alias  string          // Alias for the aggregation result
+	Filter *QueryFilter    `json:",omitempty"` // Only aggregate rows matching this filter, e.g. SUM("balance") FILTER (WHERE ...)
+	Distinct bool          `json:",omitempty"` // Aggregate distinct values only, e.g. COUNT(DISTINCT "user_id")
 }
 
 // WindowFunction defines a window function operation.
@@ -185,8 +368,18 @@ type QueryHint struct {
 	Seconds       int    `json:",omitempty"`      // For max_execution_time
 }
 
+// ScoreConfiguration adds a relevance score computed by a registered
+// GoScoreFunction to every returned row. Scores are computed in the
+// PipelineStageScore stage, after all filters and before sorting.
+type ScoreConfiguration struct {
+	Function string        // Name of the registered score function
+	Alias    string        `json:",omitempty"` // Field holding the score; defaults to "__score"
+	Sort     SortDirection `json:",omitempty"` // Optionally order results by score, ahead of Sort
+}
+
 // QueryDSL is the main Query DSL structure.
 type QueryDSL struct {
+	Alias        string                   `json:",omitempty"` // Optional alias for the base table, required for self-joins
 	Filters      *QueryFilter             `json:",omitempty"`
 	Sort         []SortConfiguration      `json:",omitempty"`
 	Pagination   *PaginationOptions       `json:",omitempty"`
@@ -195,15 +388,62 @@ type QueryDSL struct {
 	Aggregations []AggregationConfiguration `json:",omitempty"`
 	Window       []WindowFunction         `json:",omitempty"`
 	Hints        []QueryHint              `json:",omitempty"`
+	Score        *ScoreConfiguration      `json:",omitempty"`
+}
+
+// UnionRequest is one branch of a UNION query.
+type UnionRequest struct {
+	Table string    // The table this branch selects from
+	DSL   *QueryDSL // Filters and projection of the branch; its Sort and Pagination are ignored
+}
+
+// UnionQuery combines the results of several selects with UNION or UNION ALL.
+// Every branch must project the same set of columns.
+type UnionQuery struct {
+	Branches   []UnionRequest
+	All        bool                `json:",omitempty"` // UNION ALL instead of UNION (keeps duplicates)
+	Sort       []SortConfiguration `json:",omitempty"` // Applied to the combined result
+	Pagination *PaginationOptions  `json:",omitempty"` // Applied to the combined result
+}
+
+// RecursiveJoin describes how a recursive query walks a self-referencing
+// table: each step selects the rows whose ChildField equals ParentField of a
+// row already found, e.g. ChildField "manager_id" and ParentField "id" to
+// collect all reports of a manager.
+type RecursiveJoin struct {
+	ParentField string // Field of an already-found row (e.g. "id")
+	ChildField  string // Field of the next row that references it (e.g. "manager_id")
+	MaxDepth    int    `json:",omitempty"` // Maximum recursion depth; 0 means unlimited
+}
+
+// PaginationResult describes the page held by a QueryResult. Executors should
+// build it with keyed fields, since fields are added as executors report more
+// about the page.
+type PaginationResult struct {
+	Total      *int    `json:",omitempty"`
+	NextCursor *string `json:",omitempty"`
+	HasMore    bool    `json:",omitempty"` // True when rows exist beyond this page; determined by fetching one extra row instead of counting
+	Estimated  bool    `json:",omitempty"` // True when Total is an approximation rather than an exact count
 }
 
 // QueryResult structure.
 type QueryResult struct {
 	Data         any          `json:"data"` // T[] | T, could be []map[string]any
-	Pagination   *struct {
-		Total      *int    `json:",omitempty"`
-		NextCursor *string `json:",omitempty"`
-	} `json:",omitempty"`
+	Pagination   *PaginationResult `json:",omitempty"`
 	Aggregations map[string]any `json:",omitempty"`
 	Window       map[string]any `json:",omitempty"`
+	Timing       *QueryTiming   `json:",omitempty"` // Only populated when the executor has timing enabled
+	// Unprojected holds the full rows as fetched and computed, before the
+	// final projection, for hooks that depend on columns the client did not
+	// request (e.g. row-level authorization). Executors only populate it when
+	// explicitly enabled, since it keeps a second copy of every row in memory.
+	Unprojected []Row `json:"-"`
+}
+
+// QueryTiming breaks down how long each stage of a query took.
+type QueryTiming struct {
+	SQL      time.Duration // Generating and executing the SQL query, including reading rows
+	GoFilter time.Duration // Applying Go filter functions
+	Compute  time.Duration // Applying Go compute functions
+	Total    time.Duration // The whole Query call
 }
@@ -1,5 +1,11 @@
 package core
 
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
 // Add a helper function to core or as a method on ComparisonOperator
 // to distinguish standard vs. custom operators.
 // For this example, let's just make a simple map for demonstration.
@@ -18,6 +24,9 @@ var standardComparisonOperators = map[ComparisonOperator]struct{}{
 	ComparisonOperatorEndsWith:   {},
 	ComparisonOperatorExists:     {},
 	ComparisonOperatorNExists:    {},
+	ComparisonOperatorIs:         {},
+	ComparisonOperatorIsNot:      {},
+	ComparisonOperatorApproxEq:   {},
 }
 
 func (c ComparisonOperator) IsStandard() bool {
@@ -25,8 +34,539 @@ func (c ComparisonOperator) IsStandard() bool {
 	return ok
 }
 
+// IsStandard reports whether the filter only uses standard comparison
+// operators, including in the filters of Subquery values (see WalkFilters),
+// meaning it can be evaluated entirely by the database.
+// A nil filter is considered standard.
+func (f *QueryFilter) IsStandard() bool {
+	return walkFilter(f, func(filter *QueryFilter) error {
+		if filter.Condition != nil && !filter.Condition.Operator.IsStandard() {
+			return errNonStandardOperator
+		}
+		return nil
+	}) == nil
+}
+
+// errNonStandardOperator stops IsStandard's walk at the first custom operator.
+var errNonStandardOperator = errors.New("non-standard operator")
+
+// IsStandard reports whether every When condition of the CASE expression uses
+// only standard operators, so it can be rendered as a native SQL CASE instead
+// of being evaluated per row in Go. A nil expression is considered standard.
+func (c *CaseExpression) IsStandard() bool {
+	if c == nil {
+		return true
+	}
+	for i := range c.Cases {
+		if !c.Cases[i].When.IsStandard() {
+			return false
+		}
+	}
+	return true
+}
+
+var knownCollations = map[Collation]struct{}{
+	CollationBinary: {},
+	CollationNoCase: {},
+	CollationRTrim:  {},
+}
+
+// IsValid reports whether the collation is one of the known collating
+// sequences. Generators must reject unknown collations rather than emit them,
+// since they are rendered into the SQL text. The empty collation is valid and
+// means the column's default.
+func (c Collation) IsValid() bool {
+	if c == "" {
+		return true
+	}
+	_, ok := knownCollations[c]
+	return ok
+}
+
+var knownCastTypes = map[CastType]struct{}{
+	CastTypeInteger: {},
+	CastTypeReal:    {},
+	CastTypeText:    {},
+	CastTypeBlob:    {},
+	CastTypeNumeric: {},
+}
+
+// IsValid reports whether the cast target is a known storage class. The
+// empty cast type is valid and means no cast.
+func (c CastType) IsValid() bool {
+	if c == "" {
+		return true
+	}
+	_, ok := knownCastTypes[c]
+	return ok
+}
+
+var safeSQLFuncs = map[SQLFunc]struct{}{
+	SQLFuncNow:              {},
+	SQLFuncCurrentDate:      {},
+	SQLFuncCurrentTimestamp: {},
+}
+
+// IsValid reports whether the function is one of the whitelisted SQL
+// functions. Generators must reject any other SQLFunc value.
+func (f SQLFunc) IsValid() bool {
+	_, ok := safeSQLFuncs[f]
+	return ok
+}
+
+// IsValid reports whether the binding is a known strategy. The empty binding
+// is valid and means BooleanBindingInt.
+func (b BooleanBinding) IsValid() bool {
+	return b == "" || b == BooleanBindingInt || b == BooleanBindingString
+}
+
+// Bind converts a boolean filter value to the parameter value for this
+// binding strategy: int64 1 or 0 for BooleanBindingInt, "true" or "false" for
+// BooleanBindingString. Non-boolean values are returned unchanged. Unknown
+// bindings are an error.
+func (b BooleanBinding) Bind(value any) (any, error) {
+	if !b.IsValid() {
+		return nil, fmt.Errorf("unknown boolean binding: %s", b)
+	}
+	v, ok := value.(bool)
+	if !ok {
+		return value, nil
+	}
+	if b == BooleanBindingString {
+		if v {
+			return "true", nil
+		}
+		return "false", nil
+	}
+	if v {
+		return int64(1), nil
+	}
+	return int64(0), nil
+}
+
 // GetStandardComparisonOperators returns a map of all standard comparison operators.
 // This might be useful for external checks or initializations.
 func GetStandardComparisonOperators() map[ComparisonOperator]struct{} {
 	return standardComparisonOperators
 }
+
+// WithTieBreaker returns the sort configuration with uniqueField appended as
+// an ascending tie-breaker, unless the configuration already sorts on the
+// plain field. A CASE, cast or collated sort on uniqueField can still tie, so
+// it does not count.
+// Executors use it when paginating so that rows tied on non-unique sort
+// columns keep a stable order across pages.
+func WithTieBreaker(sort []SortConfiguration, uniqueField string) []SortConfiguration {
+	if uniqueField == "" {
+		return sort
+	}
+	for _, s := range sort {
+		if s.Field == uniqueField && s.Case == nil && s.Cast == "" && s.Collation == "" {
+			return sort
+		}
+	}
+	stable := make([]SortConfiguration, 0, len(sort)+1)
+	stable = append(stable, sort...)
+	return append(stable, SortConfiguration{Field: uniqueField, Direction: SortDirectionAsc})
+}
+
+// FlattenFilter returns a copy of the filter in which nested AND/OR groups are
+// merged into their parent when they use the same operator, so
+// AND(AND(a, b), AND(c, d)) becomes AND(a, b, c, d). Generators use it to keep
+// SQL compact and to stay clear of SQLite's expression depth limit on deeply
+// nested, client-generated trees. The input filter is not modified.
+func FlattenFilter(filter *QueryFilter) *QueryFilter {
+	if filter == nil || filter.Group == nil {
+		return filter
+	}
+	op := filter.Group.Operator
+	flattenable := op == LogicalOperatorAnd || op == LogicalOperatorOr
+
+	conditions := make([]QueryFilter, 0, len(filter.Group.Conditions))
+	for i := range filter.Group.Conditions {
+		child := FlattenFilter(&filter.Group.Conditions[i])
+		if flattenable && child.Group != nil && child.Group.Operator == op {
+			conditions = append(conditions, child.Group.Conditions...)
+			continue
+		}
+		conditions = append(conditions, *child)
+	}
+
+	flat := *filter
+	flat.Group = &FilterGroup{Operator: op, Conditions: conditions}
+	return &flat
+}
+
+// SplitFilter divides a filter into the part the database can evaluate and
+// the part that needs Go filter functions, such that the original filter is
+// equivalent to sqlPart AND goPart. Every standard condition of an AND group,
+// at any depth, is pushed into sqlPart so that Go filters run over the
+// smallest possible set of rows. OR, NOT, NOR and XOR groups containing a
+// custom operator cannot be split and go to goPart whole. Either part may be nil.
+func SplitFilter(filter *QueryFilter) (sqlPart, goPart *QueryFilter) {
+	return splitFilter(filter, (*QueryFilter).IsStandard)
+}
+
+// SplitFilterOnFields divides a filter into the part that does not reference
+// any of the given fields and the part that does, such that the original
+// filter is equivalent to rest AND matched. Executors use it with computed
+// aliases: rest can run before compute functions, while matched must be
+// evaluated with FilterRows after them. The same AND-only splitting rules as
+// SplitFilter apply. Either part may be nil.
+func SplitFilterOnFields(filter *QueryFilter, fields []string) (rest, matched *QueryFilter) {
+	set := make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		set[f] = struct{}{}
+	}
+	return splitFilter(filter, func(f *QueryFilter) bool {
+		return !referencesFields(f, set)
+	})
+}
+
+// splitFilter pushes every AND-connected branch satisfying keep into the
+// first result and everything else into the second.
+func splitFilter(filter *QueryFilter, keep func(*QueryFilter) bool) (kept, other *QueryFilter) {
+	if filter == nil {
+		return nil, nil
+	}
+	if filter.Group == nil || filter.Group.Operator != LogicalOperatorAnd {
+		if keep(filter) {
+			return filter, nil
+		}
+		return nil, filter
+	}
+
+	var keptConditions, otherConditions []QueryFilter
+	for i := range filter.Group.Conditions {
+		k, o := splitFilter(&filter.Group.Conditions[i], keep)
+		if k != nil {
+			keptConditions = append(keptConditions, *k)
+		}
+		if o != nil {
+			otherConditions = append(otherConditions, *o)
+		}
+	}
+	return andOf(keptConditions), andOf(otherConditions)
+}
+
+// referencesFields reports whether any condition of the filter uses one of
+// the fields, either as its Field or through a ColumnRef value.
+func referencesFields(filter *QueryFilter, fields map[string]struct{}) bool {
+	if filter == nil {
+		return false
+	}
+	if c := filter.Condition; c != nil {
+		if _, ok := fields[c.Field]; ok {
+			return true
+		}
+		var ref string
+		switch v := c.Value.(type) {
+		case ColumnRef:
+			ref = v.Name
+		case *ColumnRef:
+			ref = v.Name
+		}
+		if _, ok := fields[ref]; ok && ref != "" {
+			return true
+		}
+	}
+	if filter.Group != nil {
+		for i := range filter.Group.Conditions {
+			if referencesFields(&filter.Group.Conditions[i], fields) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// andOf wraps conditions in an AND group, returning nil for no conditions and
+// the condition itself when there is only one.
+func andOf(conditions []QueryFilter) *QueryFilter {
+	switch len(conditions) {
+	case 0:
+		return nil
+	case 1:
+		return &conditions[0]
+	}
+	return &QueryFilter{Group: &FilterGroup{Operator: LogicalOperatorAnd, Conditions: conditions}}
+}
+
+// CombineFilters joins the non-nil filters with the given logical operator,
+// e.g. to inject a tenant scope into a client-supplied filter. Inputs that are
+// already groups with the same operator are merged into the result rather
+// than nested. It returns nil when every input is nil. When exactly one input
+// is non-nil it is returned as-is for AND, OR and XOR, which leave a single
+// operand unchanged, and wrapped in a group for NOT and NOR, which negate it.
+func CombineFilters(op LogicalOperator, filters ...*QueryFilter) *QueryFilter {
+	var present []*QueryFilter
+	for _, f := range filters {
+		if f != nil {
+			present = append(present, f)
+		}
+	}
+	if len(present) == 0 {
+		return nil
+	}
+	if len(present) == 1 && (op == LogicalOperatorAnd || op == LogicalOperatorOr || op == LogicalOperatorXor) {
+		return present[0]
+	}
+
+	conditions := make([]QueryFilter, 0, len(present))
+	for _, f := range present {
+		if f.Group != nil && f.Group.Operator == op && (op == LogicalOperatorAnd || op == LogicalOperatorOr) {
+			conditions = append(conditions, f.Group.Conditions...)
+			continue
+		}
+		conditions = append(conditions, *f)
+	}
+	return &QueryFilter{Group: &FilterGroup{Operator: op, Conditions: conditions}}
+}
+
+// ValidateOperators checks every condition of the QueryDSL (see WalkFilters)
+// against the allowed operators and returns an ErrOperatorNotAllowed for the
+// first one that is not in the set. Executors use it to enforce
+// per-deployment operator policies.
+func ValidateOperators(dsl *QueryDSL, allowed []ComparisonOperator) error {
+	set := make(map[ComparisonOperator]struct{}, len(allowed))
+	for _, op := range allowed {
+		set[op] = struct{}{}
+	}
+	return WalkFilters(dsl, func(filter *QueryFilter) error {
+		if c := filter.Condition; c != nil {
+			if _, ok := set[c.Operator]; !ok {
+				return ErrOperatorNotAllowed{Operator: c.Operator, Field: c.Field}
+			}
+		}
+		return nil
+	})
+}
+
+// WalkFilters calls fn for every filter node of the QueryDSL, parents before
+// their children: the query's Filters, join conditions, aggregation filters,
+// the When conditions of CASE expressions in Sort, Window ordering and
+// projections (including nested and join projections), and recursively the
+// filters of Subquery values used in conditions. It stops at and returns the
+// first error fn returns. Validation and policies use it so that no nested
+// filter escapes them.
+func WalkFilters(dsl *QueryDSL, fn func(filter *QueryFilter) error) error {
+	if dsl == nil {
+		return nil
+	}
+	if err := walkFilter(dsl.Filters, fn); err != nil {
+		return err
+	}
+	for i := range dsl.Joins {
+		if err := walkFilter(&dsl.Joins[i].On, fn); err != nil {
+			return err
+		}
+		if err := walkProjectionFilters(dsl.Joins[i].Projection, fn); err != nil {
+			return err
+		}
+	}
+	for i := range dsl.Aggregations {
+		if err := walkFilter(dsl.Aggregations[i].Filter, fn); err != nil {
+			return err
+		}
+	}
+	if err := walkSortFilters(dsl.Sort, fn); err != nil {
+		return err
+	}
+	for i := range dsl.Window {
+		if err := walkSortFilters(dsl.Window[i].OrderBy, fn); err != nil {
+			return err
+		}
+	}
+	return walkProjectionFilters(dsl.Projection, fn)
+}
+
+func walkFilter(filter *QueryFilter, fn func(*QueryFilter) error) error {
+	if filter == nil {
+		return nil
+	}
+	if err := fn(filter); err != nil {
+		return err
+	}
+	if c := filter.Condition; c != nil {
+		switch v := c.Value.(type) {
+		case Subquery:
+			return WalkFilters(v.DSL, fn)
+		case *Subquery:
+			if v != nil {
+				return WalkFilters(v.DSL, fn)
+			}
+		}
+	}
+	if filter.Group != nil {
+		for i := range filter.Group.Conditions {
+			if err := walkFilter(&filter.Group.Conditions[i], fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func walkCaseFilters(c *CaseExpression, fn func(*QueryFilter) error) error {
+	if c == nil {
+		return nil
+	}
+	for i := range c.Cases {
+		if err := walkFilter(&c.Cases[i].When, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkSortFilters(sort []SortConfiguration, fn func(*QueryFilter) error) error {
+	for i := range sort {
+		if err := walkCaseFilters(sort[i].Case, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkProjectionFilters(p *ProjectionConfiguration, fn func(*QueryFilter) error) error {
+	if p == nil {
+		return nil
+	}
+	for i := range p.Computed {
+		if err := walkCaseFilters(p.Computed[i].CaseExpression, fn); err != nil {
+			return err
+		}
+	}
+	for _, fields := range [][]ProjectionField{p.Include, p.Exclude} {
+		for i := range fields {
+			if err := walkProjectionFilters(fields[i].Nested, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// inverseOperators maps standard operators to the operator equivalent to
+// their negation under SQL's three-valued logic: NOT (age = 25) and
+// age <> 25 are both unknown, so neither matches, when age is NULL.
+var inverseOperators = map[ComparisonOperator]ComparisonOperator{
+	ComparisonOperatorEq:          ComparisonOperatorNeq,
+	ComparisonOperatorNeq:         ComparisonOperatorEq,
+	ComparisonOperatorIn:          ComparisonOperatorNin,
+	ComparisonOperatorNin:         ComparisonOperatorIn,
+	ComparisonOperatorContains:    ComparisonOperatorNotContains,
+	ComparisonOperatorNotContains: ComparisonOperatorContains,
+	ComparisonOperatorExists:      ComparisonOperatorNotExists,
+	ComparisonOperatorNotExists:   ComparisonOperatorExists,
+	ComparisonOperatorIs:          ComparisonOperatorIsNot,
+	ComparisonOperatorIsNot:       ComparisonOperatorIs,
+}
+
+// Negate returns a filter equivalent to NOT filter. It matches the rows for
+// which filter is false; rows for which filter is unknown, such as NULL
+// comparisons, match neither. When the filter is a single condition whose
+// operator has a direct inverse (eq and neq, in and nin, contains and
+// ncontains, exists and nexists, is and isnot) the operator is inverted
+// instead of wrapping the condition in a NOT group, and negating a NOT group
+// cancels the double negation. The result never shares conditions or groups
+// with the input, which is not modified.
+func Negate(filter *QueryFilter) *QueryFilter {
+	if filter == nil {
+		return nil
+	}
+	if g := filter.Group; g != nil && g.Operator == LogicalOperatorNot && len(g.Conditions) == 1 {
+		inner := cloneFilter(g.Conditions[0])
+		return &inner
+	}
+	if c := filter.Condition; c != nil && !c.IncludeNulls {
+		if inverse, ok := inverseOperators[c.Operator]; ok {
+			negated := *c
+			negated.Operator = inverse
+			return &QueryFilter{Condition: &negated}
+		}
+	}
+	return &QueryFilter{Group: &FilterGroup{
+		Operator:   LogicalOperatorNot,
+		Conditions: []QueryFilter{cloneFilter(*filter)},
+	}}
+}
+
+// cloneFilter returns a deep copy of the filter's condition and group tree.
+// Condition values are shared.
+func cloneFilter(filter QueryFilter) QueryFilter {
+	if filter.Condition != nil {
+		c := *filter.Condition
+		filter.Condition = &c
+	}
+	if filter.Group != nil {
+		g := *filter.Group
+		g.Conditions = make([]QueryFilter, len(filter.Group.Conditions))
+		for i, child := range filter.Group.Conditions {
+			g.Conditions[i] = cloneFilter(child)
+		}
+		filter.Group = &g
+	}
+	if filter.Raw != nil {
+		r := *filter.Raw
+		filter.Raw = &r
+	}
+	return filter
+}
+
+// PushDownNegations returns a copy of the filter in which every NOT group
+// with a single child is replaced by Negate of that child wherever this
+// yields a simpler filter, e.g. NOT (age eq 25) becomes age neq 25. The
+// rewrite preserves three-valued semantics, so FilterRows returns the same
+// rows for the input and the result. Generators use it to keep leaf
+// conditions index-friendly.
+func PushDownNegations(filter *QueryFilter) *QueryFilter {
+	if filter == nil || filter.Group == nil {
+		return filter
+	}
+	conditions := make([]QueryFilter, len(filter.Group.Conditions))
+	for i := range filter.Group.Conditions {
+		conditions[i] = *PushDownNegations(&filter.Group.Conditions[i])
+	}
+	if filter.Group.Operator == LogicalOperatorNot && len(conditions) == 1 {
+		return Negate(&conditions[0])
+	}
+	pushed := *filter
+	pushed.Group = &FilterGroup{Operator: filter.Group.Operator, Conditions: conditions}
+	return &pushed
+}
+
+// ChangedFilter returns a filter matching rows where at least one of the given
+// columns differs from its value, rendered as ("a" IS NOT ? OR "b" IS NOT ? ...).
+// The comparison is NULL-safe, so a NULL column differs from a non-NULL value.
+// Conditions are ordered by column name for stable SQL. For an empty map no
+// column can differ, so it returns an empty OR group, which matches no rows;
+// it never returns nil, which would match every row.
+func ChangedFilter(values map[string]any) *QueryFilter {
+	if len(values) == 0 {
+		return &QueryFilter{Group: &FilterGroup{Operator: LogicalOperatorOr, Conditions: []QueryFilter{}}}
+	}
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	conditions := make([]QueryFilter, 0, len(columns))
+	for _, column := range columns {
+		conditions = append(conditions, QueryFilter{Condition: &FilterCondition{
+			Field:    column,
+			Operator: ComparisonOperatorIsNot,
+			Value:    values[column],
+		}})
+	}
+	return CombineFilters(LogicalOperatorOr, filterPtrs(conditions)...)
+}
+
+func filterPtrs(filters []QueryFilter) []*QueryFilter {
+	ptrs := make([]*QueryFilter, len(filters))
+	for i := range filters {
+		ptrs[i] = &filters[i]
+	}
+	return ptrs
+}
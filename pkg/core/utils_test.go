@@ -0,0 +1,525 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func cond(field string, op ComparisonOperator, value FilterValue) QueryFilter {
+	return QueryFilter{Condition: &FilterCondition{Field: field, Operator: op, Value: value}}
+}
+
+func group(op LogicalOperator, filters ...QueryFilter) QueryFilter {
+	return QueryFilter{Group: &FilterGroup{Operator: op, Conditions: filters}}
+}
+
+func TestWithTieBreaker(t *testing.T) {
+	byScore := []SortConfiguration{{Field: "score", Direction: SortDirectionDesc}}
+	got := WithTieBreaker(byScore, "id")
+	want := []SortConfiguration{{Field: "score", Direction: SortDirectionDesc}, {Field: "id", Direction: SortDirectionAsc}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if len(byScore) != 1 {
+		t.Error("WithTieBreaker modified its input")
+	}
+
+	unchanged := map[string][]SortConfiguration{
+		"already sorted on id": {{Field: "id", Direction: SortDirectionDesc}},
+		"id among others":      {{Field: "score", Direction: SortDirectionDesc}, {Field: "id", Direction: SortDirectionDesc}},
+	}
+	for name, sort := range unchanged {
+		if got := WithTieBreaker(sort, "id"); !reflect.DeepEqual(got, sort) {
+			t.Errorf("%s: got %+v, want it unchanged", name, got)
+		}
+	}
+	if got := WithTieBreaker(byScore, ""); !reflect.DeepEqual(got, byScore) {
+		t.Errorf("no unique field: got %+v", got)
+	}
+	collated := []SortConfiguration{{Field: "id", Direction: SortDirectionAsc, Collation: CollationNoCase}}
+	if got := WithTieBreaker(collated, "id"); len(got) != 2 || got[1].Collation != "" {
+		t.Errorf("collated sort on id: got %+v, want a plain id tie-breaker appended", got)
+	}
+	byCase := []SortConfiguration{{Field: "id", Case: &CaseExpression{Cases: []CaseCondition{{When: cond("score", ComparisonOperatorGt, 5), Then: 0}}, Else: 1}}}
+	if got := WithTieBreaker(byCase, "id"); len(got) != 2 || got[1].Case != nil {
+		t.Errorf("CASE sort on id: got %+v, want the tie-breaker appended", got)
+	}
+	cast := []SortConfiguration{{Field: "id", Direction: SortDirectionAsc, Cast: CastTypeText}}
+	if got := WithTieBreaker(cast, "id"); len(got) != 2 || got[1].Cast != "" {
+		t.Errorf("cast sort on id: got %+v, want a plain id tie-breaker appended", got)
+	}
+}
+
+func TestWithTieBreakerPagination(t *testing.T) {
+	rows := []Row{
+		{"id": int64(3), "score": int64(5)},
+		{"id": int64(1), "score": int64(7)},
+		{"id": int64(4), "score": int64(5)},
+		{"id": int64(2), "score": int64(5)},
+	}
+	sort := WithTieBreaker([]SortConfiguration{{Field: "score", Direction: SortDirectionDesc}}, "id")
+
+	// The database may return tied rows in any order; every order must give
+	// the same pages.
+	orders := [][]int{{0, 1, 2, 3}, {3, 2, 1, 0}, {2, 0, 3, 1}}
+	for _, order := range orders {
+		fetched := make([]Row, len(order))
+		for i, j := range order {
+			fetched[i] = rows[j]
+		}
+		if err := SortRows(fetched, sort, EvalOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		page1, page2 := ids(fetched[:2]), ids(fetched[2:])
+		if !equalIDs(page1, []int64{1, 2}) || !equalIDs(page2, []int64{3, 4}) {
+			t.Errorf("input order %v: got pages %v and %v, want [1 2] and [3 4]", order, page1, page2)
+		}
+	}
+}
+
+func TestCaseExpressionIsStandard(t *testing.T) {
+	var nilCase *CaseExpression
+	if !nilCase.IsStandard() {
+		t.Error("nil CASE expression: want standard")
+	}
+	custom := &CaseExpression{Cases: []CaseCondition{{When: cond("name", "fuzzy", "x"), Then: 1}}}
+	if custom.IsStandard() {
+		t.Error("CASE with a custom operator: want non-standard")
+	}
+}
+
+func TestFlattenFilter(t *testing.T) {
+	// AND(c0, AND(c1, AND(c2, ...))), 50 levels deep.
+	deep := cond("f49", ComparisonOperatorEq, 49)
+	for i := 48; i >= 0; i-- {
+		deep = group(LogicalOperatorAnd, cond(fmt.Sprintf("f%d", i), ComparisonOperatorEq, i), deep)
+	}
+	flat := FlattenFilter(&deep)
+	if flat.Group == nil || flat.Group.Operator != LogicalOperatorAnd || len(flat.Group.Conditions) != 50 {
+		t.Fatalf("deep AND chain: got %+v, want one AND group of 50 conditions", flat)
+	}
+	for i, c := range flat.Group.Conditions {
+		if c.Condition == nil || c.Condition.Field != fmt.Sprintf("f%d", i) {
+			t.Fatalf("condition %d: got %+v", i, c)
+		}
+	}
+	if deep.Group.Conditions[1].Group == nil {
+		t.Error("FlattenFilter modified its input")
+	}
+
+	a := cond("a", ComparisonOperatorEq, 1)
+	b := cond("b", ComparisonOperatorEq, 2)
+	c := cond("c", ComparisonOperatorEq, 3)
+	d := cond("d", ComparisonOperatorEq, 4)
+	tests := []struct {
+		name   string
+		filter QueryFilter
+		want   QueryFilter
+	}{
+		{"or inside and is kept",
+			group(LogicalOperatorAnd, a, group(LogicalOperatorOr, b, group(LogicalOperatorOr, c, d))),
+			group(LogicalOperatorAnd, a, group(LogicalOperatorOr, b, c, d))},
+		{"and inside or inside and is kept",
+			group(LogicalOperatorAnd, group(LogicalOperatorOr, a, group(LogicalOperatorAnd, b, c)), d),
+			group(LogicalOperatorAnd, group(LogicalOperatorOr, a, group(LogicalOperatorAnd, b, c)), d)},
+		{"not groups stay intact",
+			group(LogicalOperatorNot, group(LogicalOperatorNot, a)),
+			group(LogicalOperatorNot, group(LogicalOperatorNot, a))},
+		{"and inside not is flattened within it",
+			group(LogicalOperatorNot, group(LogicalOperatorAnd, a, group(LogicalOperatorAnd, b, c))),
+			group(LogicalOperatorNot, group(LogicalOperatorAnd, a, b, c))},
+		{"condition", a, a},
+	}
+	for _, tt := range tests {
+		if got := FlattenFilter(&tt.filter); !reflect.DeepEqual(*got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCollationIsValid(t *testing.T) {
+	tests := []struct {
+		collation Collation
+		want      bool
+	}{
+		{"", true},
+		{CollationBinary, true},
+		{CollationNoCase, true},
+		{CollationRTrim, true},
+		{"nocase", false},
+		{"UNICODE", false},
+		{`NOCASE; DROP TABLE users`, false},
+	}
+	for _, tt := range tests {
+		if got := tt.collation.IsValid(); got != tt.want {
+			t.Errorf("Collation(%q).IsValid() = %v, want %v", tt.collation, got, tt.want)
+		}
+	}
+}
+
+func TestSplitFilterSubqueryWithCustomOperator(t *testing.T) {
+	inOrders := cond("id", ComparisonOperatorIn, &Subquery{Table: "orders", DSL: &QueryDSL{
+		Filters: &QueryFilter{Group: &FilterGroup{Operator: LogicalOperatorAnd, Conditions: []QueryFilter{cond("note", "fuzzy", "gift")}}},
+	}})
+	if inOrders.IsStandard() {
+		t.Error("subquery with a custom operator: want non-standard")
+	}
+	filter := group(LogicalOperatorAnd, cond("age", ComparisonOperatorGt, 26), inOrders)
+	sqlPart, goPart := SplitFilter(&filter)
+	if sqlPart == nil || sqlPart.Condition == nil || sqlPart.Condition.Field != "age" {
+		t.Errorf("SQL part: got %+v, want only the age condition", sqlPart)
+	}
+	if goPart == nil || goPart.Condition == nil || goPart.Condition.Field != "id" {
+		t.Errorf("Go part: got %+v, want the subquery condition", goPart)
+	}
+}
+
+func TestCombineFilters(t *testing.T) {
+	a := cond("a", ComparisonOperatorEq, 1)
+	b := cond("b", ComparisonOperatorEq, 2)
+	c := cond("c", ComparisonOperatorEq, 3)
+	ab := group(LogicalOperatorAnd, a, b)
+
+	if CombineFilters(LogicalOperatorAnd, nil, nil) != nil {
+		t.Error("all nil inputs: want nil")
+	}
+	for _, op := range []LogicalOperator{LogicalOperatorAnd, LogicalOperatorOr, LogicalOperatorXor} {
+		if got := CombineFilters(op, nil, &a); got != &a {
+			t.Errorf("%s with one input: got %+v, want the input", op, got)
+		}
+	}
+	for _, op := range []LogicalOperator{LogicalOperatorNot, LogicalOperatorNor} {
+		got := CombineFilters(op, &a, nil)
+		if got.Group == nil || got.Group.Operator != op || !reflect.DeepEqual(got.Group.Conditions, []QueryFilter{a}) {
+			t.Errorf("%s with one input: got %+v, want it wrapped", op, got)
+		}
+	}
+
+	merged := CombineFilters(LogicalOperatorAnd, &ab, &c)
+	if want := group(LogicalOperatorAnd, a, b, c); !reflect.DeepEqual(*merged, want) {
+		t.Errorf("AND of AND group: got %+v, want flattened %+v", merged, want)
+	}
+	nested := CombineFilters(LogicalOperatorOr, &ab, &c)
+	if want := group(LogicalOperatorOr, ab, c); !reflect.DeepEqual(*nested, want) {
+		t.Errorf("OR of AND group: got %+v, want %+v", nested, want)
+	}
+	notGroup := group(LogicalOperatorNot, a)
+	if got := CombineFilters(LogicalOperatorNot, &notGroup, &b); len(got.Group.Conditions) != 2 || got.Group.Conditions[0].Group == nil {
+		t.Errorf("NOT groups must not be merged: got %+v", got)
+	}
+}
+
+func TestBooleanBindingBind(t *testing.T) {
+	tests := []struct {
+		binding BooleanBinding
+		value   any
+		want    any
+	}{
+		{"", true, int64(1)},
+		{BooleanBindingInt, false, int64(0)},
+		{BooleanBindingString, true, "true"},
+		{BooleanBindingString, false, "false"},
+		{BooleanBindingString, int64(1), int64(1)},
+	}
+	for _, tt := range tests {
+		got, err := tt.binding.Bind(tt.value)
+		if err != nil {
+			t.Errorf("%q.Bind(%v): %v", tt.binding, tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q.Bind(%v) = %#v, want %#v", tt.binding, tt.value, got, tt.want)
+		}
+	}
+	if _, err := BooleanBinding("yes_no").Bind(true); err == nil {
+		t.Error("unknown binding: expected an error")
+	}
+}
+
+// nestedFilterDSL places a condition with the given operator, on a field
+// named after its location, in every filter-bearing part of a QueryDSL.
+func nestedFilterDSL(op ComparisonOperator) map[string]*QueryDSL {
+	at := func(field string) QueryFilter { return cond(field, op, "x") }
+	caseOn := func(field string) *CaseExpression {
+		return &CaseExpression{Cases: []CaseCondition{{When: at(field), Then: 1}}, Else: 0}
+	}
+	filters := at("filters")
+	aggregate := at("aggregate")
+	subquery := cond("id", ComparisonOperatorIn, Subquery{Table: "orders", DSL: &QueryDSL{
+		Filters: &QueryFilter{Group: &FilterGroup{Operator: LogicalOperatorAnd, Conditions: []QueryFilter{at("subquery")}}},
+	}})
+	return map[string]*QueryDSL{
+		"filters":         {Filters: &filters},
+		"group":           {Filters: &QueryFilter{Group: &FilterGroup{Operator: LogicalOperatorOr, Conditions: []QueryFilter{at("group")}}}},
+		"join":            {Joins: []JoinConfiguration{{TargetTable: "orders", On: at("join")}}},
+		"aggregate":       {Aggregations: []AggregationConfiguration{{Type: AggregationTypeCount, Field: "id", Filter: &aggregate}}},
+		"projection":      {Projection: &ProjectionConfiguration{Computed: []ProjectionComputedItem{{CaseExpression: caseOn("projection")}}}},
+		"nested":          {Projection: &ProjectionConfiguration{Include: []ProjectionField{{Name: "profile", Nested: &ProjectionConfiguration{Computed: []ProjectionComputedItem{{CaseExpression: caseOn("nested")}}}}}}},
+		"join projection": {Joins: []JoinConfiguration{{TargetTable: "orders", Projection: &ProjectionConfiguration{Computed: []ProjectionComputedItem{{CaseExpression: caseOn("join projection")}}}}}},
+		"subquery":        {Filters: &subquery},
+		"sort":            {Sort: []SortConfiguration{{Case: caseOn("sort")}}},
+		"window":          {Window: []WindowFunction{{Function: "ROW_NUMBER", OrderBy: []SortConfiguration{{Case: caseOn("window")}}}}},
+	}
+}
+
+func TestValidateOperatorsVisitsNestedFilters(t *testing.T) {
+	allowed := []ComparisonOperator{ComparisonOperatorEq, ComparisonOperatorIn}
+	for location, dsl := range nestedFilterDSL(ComparisonOperatorContains) {
+		err := ValidateOperators(dsl, allowed)
+		var notAllowed ErrOperatorNotAllowed
+		if !errors.As(err, &notAllowed) {
+			t.Errorf("%s: got %v, want ErrOperatorNotAllowed", location, err)
+			continue
+		}
+		if notAllowed.Field != location {
+			t.Errorf("%s: rejected field %q", location, notAllowed.Field)
+		}
+	}
+	for location, dsl := range nestedFilterDSL(ComparisonOperatorEq) {
+		if err := ValidateOperators(dsl, allowed); err != nil {
+			t.Errorf("%s: unexpected error %v", location, err)
+		}
+	}
+	if err := ValidateOperators(nil, allowed); err != nil {
+		t.Errorf("nil QueryDSL: unexpected error %v", err)
+	}
+}
+
+func TestNegate(t *testing.T) {
+	eq := cond("age", ComparisonOperatorEq, 25)
+	negated := Negate(&eq)
+	if negated.Condition == nil || negated.Condition.Operator != ComparisonOperatorNeq {
+		t.Fatalf("NOT (age eq 25): got %+v, want age neq 25", negated)
+	}
+	if eq.Condition.Operator != ComparisonOperatorEq {
+		t.Error("Negate modified its input")
+	}
+
+	notGroup := group(LogicalOperatorNot, cond("name", "fuzzy", "x"))
+	inner := Negate(&notGroup)
+	if !reflect.DeepEqual(*inner, notGroup.Group.Conditions[0]) {
+		t.Fatalf("double negation: got %+v, want the inner condition", inner)
+	}
+	inner.Condition.Value = "changed"
+	if notGroup.Group.Conditions[0].Condition.Value != "x" {
+		t.Error("double negation shares its condition with the input")
+	}
+
+	custom := cond("name", "fuzzy", "x")
+	wrapped := Negate(&custom)
+	if wrapped.Group == nil || wrapped.Group.Operator != LogicalOperatorNot {
+		t.Fatalf("custom operator: got %+v, want a NOT group", wrapped)
+	}
+	if Negate(Negate(&custom)).Condition.Operator != "fuzzy" {
+		t.Error("negating twice does not cancel")
+	}
+
+	withNulls := QueryFilter{Condition: &FilterCondition{Field: "age", Operator: ComparisonOperatorEq, Value: 25, IncludeNulls: true}}
+	if Negate(&withNulls).Group == nil {
+		t.Error("IncludeNulls condition: want a NOT group")
+	}
+	if Negate(nil) != nil {
+		t.Error("Negate(nil) != nil")
+	}
+}
+
+func TestSQLFuncIsValid(t *testing.T) {
+	tests := []struct {
+		fn   SQLFunc
+		want bool
+	}{
+		{SQLFuncNow, true},
+		{SQLFuncCurrentDate, true},
+		{SQLFuncCurrentTimestamp, true},
+		{"", false},
+		{"now", false},
+		{"RANDOM", false},
+		{"load_extension('x')", false},
+	}
+	for _, tt := range tests {
+		if got := tt.fn.IsValid(); got != tt.want {
+			t.Errorf("SQLFunc(%q).IsValid() = %v, want %v", tt.fn, got, tt.want)
+		}
+	}
+}
+
+func TestCastTypeIsValid(t *testing.T) {
+	tests := []struct {
+		cast CastType
+		want bool
+	}{
+		{"", true},
+		{CastTypeInteger, true},
+		{CastTypeReal, true},
+		{CastTypeText, true},
+		{CastTypeBlob, true},
+		{CastTypeNumeric, true},
+		{"integer", false},
+		{"DATE", false},
+		{"INTEGER) --", false},
+	}
+	for _, tt := range tests {
+		if got := tt.cast.IsValid(); got != tt.want {
+			t.Errorf("CastType(%q).IsValid() = %v, want %v", tt.cast, got, tt.want)
+		}
+	}
+}
+
+func TestSplitFilterNarrowsGoFilterRows(t *testing.T) {
+	calls := 0
+	opts := EvalOptions{Filters: map[ComparisonOperator]GoFilterFunction{
+		"short_name": func(row Row) (bool, error) {
+			calls++
+			name, _ := row["name"].(string)
+			return len(name) <= 3, nil
+		},
+	}}
+	// age > 26 AND (short_name AND name IS NOT NULL)
+	filter := group(LogicalOperatorAnd,
+		cond("age", ComparisonOperatorGt, 26),
+		group(LogicalOperatorAnd, cond("name", "short_name", nil), cond("name", ComparisonOperatorExists, nil)),
+	)
+	sqlPart, goPart := SplitFilter(&filter)
+	if !sqlPart.IsStandard() {
+		t.Fatalf("SQL part uses a custom operator: %+v", sqlPart)
+	}
+	if goPart == nil || goPart.Condition == nil || goPart.Condition.Operator != "short_name" {
+		t.Fatalf("Go part: got %+v, want only the short_name condition", goPart)
+	}
+
+	// The database runs the SQL part; only the rows it returns reach Go.
+	narrowed, err := FilterRows(filterTestRows, sqlPart, EvalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := FilterRows(narrowed, goPart, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{2}; !equalIDs(ids(got), want) {
+		t.Errorf("got ids %v, want %v", ids(got), want)
+	}
+	if calls != len(narrowed) || calls != 1 {
+		t.Errorf("Go filter ran on %d rows, want the %d narrowed rows instead of %d", calls, len(narrowed), len(filterTestRows))
+	}
+}
+
+func TestPushDownNegationsPreservesFilterRows(t *testing.T) {
+	filters := []QueryFilter{
+		group(LogicalOperatorNot, cond("age", ComparisonOperatorEq, 25)),
+		group(LogicalOperatorNot, cond("age", ComparisonOperatorNeq, 25)),
+		group(LogicalOperatorNot, cond("age", ComparisonOperatorIn, []any{25, nil})),
+		group(LogicalOperatorNot, cond("age", ComparisonOperatorNin, []any{25})),
+		group(LogicalOperatorNot, cond("name", ComparisonOperatorContains, "b")),
+		group(LogicalOperatorNot, cond("age", ComparisonOperatorExists, nil)),
+		group(LogicalOperatorNot, cond("age", ComparisonOperatorIs, nil)),
+		group(LogicalOperatorNot, group(LogicalOperatorNot, cond("age", ComparisonOperatorLt, 35))),
+		group(LogicalOperatorOr,
+			group(LogicalOperatorNot, cond("age", ComparisonOperatorEq, 30)),
+			group(LogicalOperatorNot, cond("name", ComparisonOperatorEq, "carol")),
+		),
+		group(LogicalOperatorNot, QueryFilter{Condition: &FilterCondition{
+			Field: "age", Operator: ComparisonOperatorEq, Value: 25, IncludeNulls: true,
+		}}),
+	}
+	for _, filter := range filters {
+		pushed := PushDownNegations(&filter)
+		want, err := FilterRows(filterTestRows, &filter, EvalOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := FilterRows(filterTestRows, pushed, EvalOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equalIDs(ids(got), ids(want)) {
+			t.Errorf("%+v: pushed filter matches %v, original matches %v", filter, ids(got), ids(want))
+		}
+	}
+
+	filter := group(LogicalOperatorNot, cond("age", ComparisonOperatorEq, 25))
+	if pushed := PushDownNegations(&filter); pushed.Condition == nil || pushed.Condition.Operator != ComparisonOperatorNeq {
+		t.Errorf("NOT (age eq 25): got %+v, want age neq 25", pushed)
+	}
+}
+
+func TestChangedFilter(t *testing.T) {
+	rows := []Row{
+		{"id": int64(1), "name": "alice", "email": "a@example.com"},
+		{"id": int64(2), "name": "alice", "email": nil},
+		{"id": int64(3), "name": "alicia", "email": "a@example.com"},
+		{"id": int64(4), "name": nil, "email": "a@example.com"},
+	}
+	tests := []struct {
+		name   string
+		values map[string]any
+		want   []int64
+	}{
+		{"differs in any column", map[string]any{"name": "alice", "email": "a@example.com"}, []int64{2, 3, 4}},
+		{"null differs from a value", map[string]any{"email": nil}, []int64{1, 3, 4}},
+		{"no columns", map[string]any{}, nil},
+	}
+	for _, tt := range tests {
+		filter := ChangedFilter(tt.values)
+		if filter == nil {
+			t.Fatalf("%s: got a nil filter", tt.name)
+		}
+		got, err := FilterRows(rows, filter, EvalOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equalIDs(ids(got), tt.want) {
+			t.Errorf("%s: got ids %v, want %v", tt.name, ids(got), tt.want)
+		}
+	}
+
+	filter := ChangedFilter(map[string]any{"name": "alice", "email": "a@example.com"})
+	if filter.Group == nil || filter.Group.Operator != LogicalOperatorOr || filter.Group.Conditions[0].Condition.Field != "email" {
+		t.Errorf("want an OR group ordered by column, got %+v", filter)
+	}
+}
+
+func TestSplitFilterOnComputedAlias(t *testing.T) {
+	rows := []Row{
+		{"id": int64(1), "price": int64(100), "pct": int64(10), "cap": int64(20), "stock": int64(1)},
+		{"id": int64(2), "price": int64(40), "pct": int64(10), "cap": int64(20), "stock": int64(3)},
+		{"id": int64(3), "price": int64(200), "pct": int64(5), "cap": int64(8), "stock": int64(2)},
+		{"id": int64(4), "price": int64(300), "pct": int64(20), "cap": int64(50), "stock": int64(0)},
+		{"id": int64(5), "price": int64(80), "pct": int64(25), "cap": int64(30), "stock": int64(5)},
+	}
+	// stock > 0 AND discount > 5 AND cap >= discount
+	filter := group(LogicalOperatorAnd,
+		cond("stock", ComparisonOperatorGt, 0),
+		cond("discount", ComparisonOperatorGt, 5),
+		cond("cap", ComparisonOperatorGte, ColumnRef{Name: "discount"}),
+	)
+	rest, matched := SplitFilterOnFields(&filter, []string{"discount"})
+	if rest == nil || rest.Condition == nil || rest.Condition.Field != "stock" {
+		t.Fatalf("rest: got %+v, want only the stock condition", rest)
+	}
+	if matched == nil || matched.Group == nil || len(matched.Group.Conditions) != 2 {
+		t.Fatalf("matched: got %+v, want both conditions on discount", matched)
+	}
+
+	// The database applies rest; discount is computed on what it returns.
+	fetched, err := FilterRows(rows, rest, EvalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range fetched {
+		row["discount"] = row["price"].(int64) * row["pct"].(int64) / 100
+	}
+	got, err := FilterRows(fetched, matched, EvalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SortRows(got, []SortConfiguration{{Field: "discount", Direction: SortDirectionDesc}}, EvalOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	// discounts: 1 -> 10, 2 -> 4, 3 -> 10 (above its cap of 8), 5 -> 20.
+	if want := []int64{5, 1}; !equalIDs(ids(got), want) {
+		t.Errorf("got ids %v, want %v", ids(got), want)
+	}
+}